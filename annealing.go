@@ -0,0 +1,130 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	// How close to win either player must be for AnnealingStrategy to search.
+	annealingRange = 25
+	// The most rolls a plan searched by AnnealingStrategy makes.
+	maxPlanRolls = 20
+	// The number of steps of each AnnealingStrategy search.
+	annealingSteps = 50
+)
+
+// AnnealingStrategy is like StayAtK, but in the endgame, once either player
+// is within annealingRange of win, it searches the plans "roll n more times,
+// then stay" by simulated annealing and rolls if the best plan it finds
+// rolls at all. A plan's value is its exact probability of winning, playing
+// optimally after the turn, assuming it stops rolling if it reaches win.
+// The search starts from the plan of staying and takes annealingSteps
+// steps, each to a plan one roll longer or shorter, accepting a worse plan
+// with probability exp(-loss/t) at temperature t, which starts at
+// temperature and is multiplied by cooling after each step. It draws from
+// its own source, and is not safe for concurrent use.
+type AnnealingStrategy struct {
+	temperature, cooling float64
+	k                    int
+	rng                  *rand.Rand
+}
+
+// NewAnnealingStrategy returns an AnnealingStrategy that stays at k before
+// the endgame, searching with the given temperature schedule and drawing
+// from its own source seeded with seed.
+func NewAnnealingStrategy(temperature, cooling float64, k int, seed int64) *AnnealingStrategy {
+	return &AnnealingStrategy{temperature, cooling, k, rand.New(rand.NewSource(seed))}
+}
+
+// planValue returns the probability of winning from s by rolling up to n
+// more times, stopping early only on a 1 or on reaching win, and then
+// staying, if both players play optimally after the turn.
+func planValue(t *winTable, s score, n int) float64 {
+	// p[turnTotal] is the probability of rolling no 1 and reaching turnTotal.
+	p := make([]float64, t.win-s.player+6)
+	p[s.thisTurn] = 1
+	busted := 0.0
+	for ; n > 0; n-- {
+		next := make([]float64, len(p))
+		for total, q := range p {
+			if q == 0 {
+				continue
+			}
+			if s.player+total >= t.win {
+				next[total] += q
+				continue
+			}
+			busted += q / 6
+			for outcome := 2; outcome <= 6; outcome++ {
+				next[total+outcome] += q / 6
+			}
+		}
+		p = next
+	}
+	v := busted * (1 - t.at(score{s.opponent, s.player, 0}))
+	for total, q := range p {
+		if q != 0 {
+			v += q * t.stayProbability(score{s.player, s.opponent, total})
+		}
+	}
+	return v
+}
+
+// bestPlan returns the number of rolls in the best plan its search from s
+// finds.
+func (self *AnnealingStrategy) bestPlan(s score) int {
+	t := optimalTable()
+	values := make(map[int]float64)
+	value := func(n int) float64 {
+		v, ok := values[n]
+		if !ok {
+			v = planValue(t, s, n)
+			values[n] = v
+		}
+		return v
+	}
+	current, best := 0, 0
+	temperature := self.temperature
+	for i := 0; i < annealingSteps; i++ {
+		next := current + 1
+		if current == maxPlanRolls || (current > 0 && self.rng.Intn(2) == 0) {
+			next = current - 1
+		}
+		loss := value(current) - value(next)
+		if loss <= 0 || (temperature > 0 && self.rng.Float64() < math.Exp(-loss/temperature)) {
+			current = next
+		}
+		if value(current) > value(best) {
+			best = current
+		}
+		temperature *= self.cooling
+	}
+	return best
+}
+
+func (self *AnnealingStrategy) nextAction(s score) action {
+	if s.player+s.thisTurn < win-annealingRange && s.opponent < win-annealingRange {
+		if s.thisTurn >= self.k {
+			return stay
+		}
+		return roll
+	}
+	if self.bestPlan(s) > 0 {
+		return roll
+	}
+	return stay
+}
+
+func (self *AnnealingStrategy) Seed(seed int64) {
+	self.rng.Seed(seed)
+}
+
+// Clone returns an AnnealingStrategy with its own source seeded from self's.
+func (self *AnnealingStrategy) Clone() Strategy {
+	return NewAnnealingStrategy(self.temperature, self.cooling, self.k, self.rng.Int63())
+}
+
+func (self *AnnealingStrategy) String() string {
+	return "Annealing"
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// A CheckpointState is the progress of a resumable round robin: which
+// series have been played and what they added up to. It can be saved with
+// encoding/gob and resumed later, even by another process, as long as the
+// lineup is the same.
+type CheckpointState struct {
+	Seed     int64
+	Games    int      // Games per series
+	Complete [][]bool // Complete[i][j], for i < j, is whether i and j have played
+	Wins     []int    // Each strategy's wins in the complete series
+}
+
+// NewCheckpointState returns the state of a round robin of games games per
+// series between strategies strategies that hasn't started yet. A seed of
+// 0 picks one from the clock, as for Simulate.
+func NewCheckpointState(strategies, games int, seed int64) CheckpointState {
+	state := CheckpointState{
+		Seed:     resolveSeed(seed),
+		Games:    games,
+		Complete: make([][]bool, strategies),
+		Wins:     make([]int, strategies),
+	}
+	for i := range state.Complete {
+		state.Complete[i] = make([]bool, strategies)
+	}
+	return state
+}
+
+// CheckpointRoundRobin plays the series of state that are not yet complete
+// between strategies, in order, until they are all done or ctx is done,
+// recording each in state's slices. It returns the state after the last
+// series it played, and ctx's error if it stopped early. Each series is
+// played by fresh clones of its two strategies, seeded from state's Seed,
+// so that its result doesn't depend on what was played before it or on
+// when the round robin was interrupted.
+func CheckpointRoundRobin(ctx context.Context, state CheckpointState, strategies []Strategy) (CheckpointState, error) {
+	if len(state.Wins) != len(strategies) || len(state.Complete) != len(strategies) {
+		return state, fmt.Errorf("checkpoint is of a round robin of %d strategies, not %d",
+			len(state.Wins), len(strategies))
+	}
+	for i := range strategies {
+		for j := i + 1; j < len(strategies); j++ {
+			if state.Complete[i][j] {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return state, err
+			}
+			c := seededSeries(clone(strategies[i]), clone(strategies[j]), state.Games, seriesSeed(state.Seed, i, j))
+			state.Wins[i] += c.WinsA
+			state.Wins[j] += c.WinsB
+			state.Complete[i][j] = true
+		}
+	}
+	return state, nil
+}
+
+// ResumeRoundRobin plays the rest of the round robin of state between
+// strategies and returns its result. The result is the same whether the
+// round robin was interrupted any number of times or not at all.
+func ResumeRoundRobin(state CheckpointState, strategies []Strategy) (SimResult, error) {
+	state, err := CheckpointRoundRobin(context.Background(), state, strategies)
+	if err != nil {
+		return SimResult{}, err
+	}
+	return newSimResult(state.Seed, strategies, state.Wins, state.Games*(len(strategies)-1)), nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"testing"
+)
+
+// countdown is a context that is done once Err has been asked n times.
+type countdown struct {
+	context.Context
+	n int
+}
+
+func (self *countdown) Err() error {
+	if self.n == 0 {
+		return context.Canceled
+	}
+	self.n--
+	return nil
+}
+
+// sameResult reports whether a and b are the same result, telling their
+// strategies apart by name.
+func sameResult(a, b SimResult) bool {
+	if a.Seed != b.Seed || a.Games != b.Games || len(a.Standings) != len(b.Standings) {
+		return false
+	}
+	for i, st := range a.Standings {
+		other := b.Standings[i]
+		if st.Strategy.String() != other.Strategy.String() ||
+			st.Wins != other.Wins || st.Losses != other.Losses || st.Draws != other.Draws {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCheckpointResumesToTheSameResult(t *testing.T) {
+	lineup := func() []Strategy {
+		return []Strategy{&StayAtK{15}, &StayAtK{20}, NewRandom(3), &StayAtK{25}}
+	}
+	want, err := ResumeRoundRobin(NewCheckpointState(4, 100, 1), lineup())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Stop after 3 of the 6 series, and save the checkpoint.
+	state, err := CheckpointRoundRobin(&countdown{context.Background(), 3}, NewCheckpointState(4, 100, 1), lineup())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("stopping early returned %v, want %v", err, context.Canceled)
+	}
+	played := 0
+	for i := range state.Complete {
+		for j := range state.Complete[i] {
+			if state.Complete[i][j] {
+				played++
+			}
+		}
+	}
+	if played != 3 {
+		t.Fatalf("played %d series before stopping, want 3", played)
+	}
+	var saved bytes.Buffer
+	if err := gob.NewEncoder(&saved).Encode(state); err != nil {
+		t.Fatal(err)
+	}
+
+	var restored CheckpointState
+	if err := gob.NewDecoder(&saved).Decode(&restored); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ResumeRoundRobin(restored, lineup())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameResult(got, want) {
+		t.Errorf("resumed round robin gave %+v, want %+v as uninterrupted", got, want)
+	}
+	if _, err := ResumeRoundRobin(restored, lineup()[:3]); err == nil {
+		t.Error("resuming with a different lineup succeeded")
+	}
+}
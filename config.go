@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// A GameConfig selects the rules of a game of Pig. The zero value is the
+// standard game.
+type GameConfig struct {
+	// Win is the winning score. If it is 0, the winning score is win.
+	Win int
+
+	// Faces is the number of faces on the die. If it is 0, the die has 6.
+	Faces int
+
+	// MustHitExact requires landing exactly on the winning score. A roll
+	// that overshoots it abandons thisTurn and passes the turn, like a 1.
+	// A player who banks one point short can never win, so a game between
+	// two strategies that both do so may never end unless MaxTurns is set.
+	MustHitExact bool
+
+	// BankHalf lets players bank half their points this turn and keep
+	// rolling, as a third action besides rolling and staying. Without it,
+	// an action that neither rolls nor ends the turn counts as staying.
+	BankHalf bool
+
+	// MaxTurns, if not 0, ends the game in a draw once that many turns
+	// have been played without a winner.
+	MaxTurns int
+
+	// Peek lets the current player see the value of the next roll before
+	// choosing an action. Strategies that are not Peekers ignore it.
+	Peek bool
+
+	// SafeFirstRoll rerolls a 1 on the first roll of each turn, once, so
+	// that it doesn't end the turn. A 1 on the reroll ends it as usual.
+	// With Peek, the reroll is made before the peek, so peekers see the
+	// roll that will count. Optimal and the other table-driven strategies
+	// don't allow for it.
+	SafeFirstRoll bool
+
+	// Start chooses who moves first in each game of a series, such as
+	// those played by PlaySeries and CompareStrategies.
+	Start StartPolicy
+	// Ratings rates strategies, keyed by name, for StartWeaker.
+	Ratings map[string]float64
+
+	// MaxRollsPerTurn, if not 0, ends a turn once that many rolls have
+	// been made in it, banking its points as if the player had stayed.
+	MaxRollsPerTurn int
+
+	// FaceMultipliers maps die faces to how many times their value they
+	// score, for faces that don't score just their value. A 1 always ends
+	// the turn, so it can't have a multiplier. Peekers see the scored
+	// value, and Optimal and the other table-driven strategies don't
+	// allow for it.
+	FaceMultipliers map[int]int
+
+	// DecisionDeadline, if not 0, makes a player who takes longer than it
+	// to choose an action forfeit the game.
+	DecisionDeadline time.Duration
+}
+
+// A StartPolicy chooses who moves first in each game of a series.
+type StartPolicy int
+
+const (
+	// StartAlternate alternates the first move, starting with the first
+	// strategy named.
+	StartAlternate StartPolicy = iota
+	// StartRandom picks who moves first at random, from the series' seed.
+	StartRandom
+	// StartLoserFirst gives the first move to the loser of the previous
+	// game, and alternates it after a draw. The first strategy named moves
+	// first in the first game.
+	StartLoserFirst
+	// StartWeaker gives the first move in every game to whichever strategy
+	// has the lower rating in Ratings, and alternates it, as StartAlternate
+	// does, between strategies rated the same. Both must be rated.
+	StartWeaker
+)
+
+// A ConfigError reports an invalid setting in a GameConfig.
+type ConfigError struct {
+	Setting string // The name of the offending GameConfig field
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid GameConfig.%s: %s", e.Setting, e.Message)
+}
+
+// Validate returns a *ConfigError describing the first invalid setting in
+// cfg, or nil if cfg is a playable game.
+func (cfg GameConfig) Validate() error {
+	if cfg.Faces != 0 && cfg.Faces < 2 {
+		return &ConfigError{"Faces", fmt.Sprintf("a die needs at least 2 faces, not %d", cfg.Faces)}
+	}
+	if cfg.Win < 0 {
+		return &ConfigError{"Win", fmt.Sprintf("%d is negative", cfg.Win)}
+	}
+	if cfg.MaxTurns < 0 {
+		return &ConfigError{"MaxTurns", fmt.Sprintf("%d is negative", cfg.MaxTurns)}
+	}
+	if cfg.Start < StartAlternate || cfg.Start > StartWeaker {
+		return &ConfigError{"Start", fmt.Sprintf("unknown policy %d", cfg.Start)}
+	}
+	if cfg.MaxRollsPerTurn < 0 {
+		return &ConfigError{"MaxRollsPerTurn", fmt.Sprintf("%d is negative", cfg.MaxRollsPerTurn)}
+	}
+	for face, m := range cfg.FaceMultipliers {
+		if face < 2 || face > cfg.faces() {
+			return &ConfigError{"FaceMultipliers", fmt.Sprintf("%d is not a scoring face", face)}
+		}
+		if m < 1 {
+			return &ConfigError{"FaceMultipliers", fmt.Sprintf("multiplier %d of face %d is not positive", m, face)}
+		}
+	}
+	if cfg.DecisionDeadline < 0 {
+		return &ConfigError{"DecisionDeadline", fmt.Sprintf("%v is negative", cfg.DecisionDeadline)}
+	}
+	return nil
+}
+
+// winningScore returns the score needed to win under cfg.
+func (cfg GameConfig) winningScore() int {
+	if cfg.Win == 0 {
+		return win
+	}
+	return cfg.Win
+}
+
+// faces returns the number of faces on the die under cfg.
+func (cfg GameConfig) faces() int {
+	if cfg.Faces == 0 {
+		return 6
+	}
+	return cfg.Faces
+}
+
+// die returns a fair die with cfg's number of faces that rolls using rng.
+func (cfg GameConfig) die(rng *rand.Rand) die {
+	faces := cfg.faces()
+	return func() int {
+		return rng.Intn(faces) + 1
+	}
+}
+
+// A Peeker is a Strategy that can make use of the next roll's value when
+// the game lets players peek.
+type Peeker interface {
+	Strategy
+	NextAction(s score, nextDie int) action
+}
+
+// decide returns strategy's action in g. If nextDie is not 0, the player
+// may peek and the next roll will be nextDie.
+func decide(strategy Strategy, g GameState, nextDie int) action {
+	if peeker, ok := strategy.(Peeker); ok && nextDie != 0 {
+		return peeker.NextAction(g.score, nextDie)
+	}
+	return actionAt(strategy, g)
+}
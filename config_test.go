@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// stayOnOne is a Peeker that rolls unless the next die is a 1.
+type stayOnOne struct{}
+
+func (stayOnOne) NextAction(s score, nextDie int) action {
+	if nextDie == 1 {
+		return stay
+	}
+	return roll
+}
+
+func (stayOnOne) nextAction(s score) action { return roll }
+
+func (stayOnOne) String() string { return "stay on one" }
+
+func TestPeekerThatStaysOnOneNeverBusts(t *testing.T) {
+	cfg := GameConfig{Peek: true}
+	dice := cfg.die(rand.New(rand.NewSource(1)))
+	for g := 0; g < 200; g++ {
+		playGame(cfg, stayOnOne{}, &StayAtK{20}, g%2, dice, func(turn Turn) {
+			if turn.Player == 0 && turn.Ended && turn.Die != 0 {
+				t.Fatalf("game %d: the peeker busted", g)
+			}
+		})
+	}
+}
+
+func TestValidateReportsTheBadSetting(t *testing.T) {
+	for _, test := range []struct {
+		cfg     GameConfig
+		setting string
+	}{
+		{GameConfig{Faces: 1}, "Faces"},
+		// FaceMultipliers depend on the die, so the die is checked first.
+		{GameConfig{Faces: 1, FaceMultipliers: map[int]int{2: 2}}, "Faces"},
+		{GameConfig{Win: -1}, "Win"},
+		{GameConfig{MaxTurns: -5}, "MaxTurns"},
+		{GameConfig{Start: StartPolicy(99)}, "Start"},
+		{GameConfig{MaxRollsPerTurn: -1}, "MaxRollsPerTurn"},
+		{GameConfig{FaceMultipliers: map[int]int{1: 2}}, "FaceMultipliers"},
+		{GameConfig{FaceMultipliers: map[int]int{7: 2}}, "FaceMultipliers"},
+		{GameConfig{FaceMultipliers: map[int]int{6: 0}}, "FaceMultipliers"},
+		{GameConfig{DecisionDeadline: -1}, "DecisionDeadline"},
+	} {
+		var cfgErr *ConfigError
+		if err := test.cfg.Validate(); !errors.As(err, &cfgErr) || cfgErr.Setting != test.setting {
+			t.Errorf("%+v: got %v, want a ConfigError for %s", test.cfg, err, test.setting)
+		}
+	}
+	if err := (GameConfig{Win: 50, Faces: 8}).Validate(); err != nil {
+		t.Errorf("a valid config got %v", err)
+	}
+}
+
+func TestMustHitExactBustsOnOvershoot(t *testing.T) {
+	cfg := GameConfig{MustHitExact: true, MaxTurns: 2}
+	var turns []Turn
+	playFrom(cfg, &StayAtK{20}, &StayAtK{20}, 0, score{95, 0, 3}, scripted(6, 1), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	if want := (Turn{0, 6, 0, true}); len(turns) == 0 || turns[0] != want {
+		t.Errorf("overshooting to 104 recorded %+v, want %+v", turns[:min(1, len(turns))], want)
+	}
+}
+
+func TestExactTargetBeatsStayAtKUnderExactRules(t *testing.T) {
+	// Even StayAtK's best k only gives ExactTarget a small edge, since most
+	// of a game is played far from win, so ExactTarget must beat every k and
+	// clearly beat them taken together.
+	cfg := GameConfig{MustHitExact: true}
+	const games = 20000
+	won := 0
+	for _, k := range []int{10, 20, 30, 40} {
+		c, err := cfg.CompareStrategies(&ExactTarget{20, win}, &StayAtK{k}, games, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.winRateA() <= 0.5 {
+			t.Errorf("ExactTarget won %.3f of its games against StayAtK{%d} under exact rules", c.winRateA(), k)
+		}
+		won += c.WinsA
+	}
+	if rate := float64(won) / (4 * games); rate < 0.55 {
+		t.Errorf("ExactTarget won %.3f of its games against StayAtK under exact rules, want at least 0.55", rate)
+	}
+}
+
+func TestSafeFirstRollRerollsAFirstOne(t *testing.T) {
+	cfg := GameConfig{SafeFirstRoll: true, MaxTurns: 2}
+	var turns []Turn
+	playFrom(cfg, &StayAtK{20}, &StayAtK{20}, 0, score{}, scripted(1, 4, 1, 1, 1), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	// Player 0's first 1 is rerolled, but its second isn't; player 1's first
+	// roll is rerolled into another 1, which ends the game's last turn.
+	want := []Turn{{0, 4, 4, false}, {0, 1, 0, true}, {1, 1, 0, true}}
+	if !slices.Equal(turns, want) {
+		t.Errorf("rolling 1, 4, 1, 1, 1 recorded %+v, want %+v", turns, want)
+	}
+}
+
+func TestMaxRollsPerTurnEndsTheTurn(t *testing.T) {
+	cfg := GameConfig{MaxRollsPerTurn: 3, MaxTurns: 2}
+	var turns []Turn
+	playFrom(cfg, &StayAtK{win}, &StayAtK{win}, 0, score{}, scripted(2, 3, 4, 5, 6, 1), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	// Player 0's third roll banks its turn; player 1 busts on its third.
+	want := []Turn{
+		{0, 2, 2, false}, {0, 3, 5, false}, {0, 4, 9, false}, {0, 0, 9, true},
+		{1, 5, 5, false}, {1, 6, 11, false}, {1, 1, 0, true},
+	}
+	if !slices.Equal(turns, want) {
+		t.Errorf("rolling 2, 3, 4, 5, 6, 1 recorded %+v, want %+v", turns, want)
+	}
+
+	cfg.MaxTurns = 0
+	for game := int64(0); game < 100; game++ {
+		rolls := 0
+		_, err := cfg.PlayGame(&StayAtK{win}, &Random{}, int(game%2), cfg.RandomDie(game), func(turn Turn) {
+			if turn.Die != 0 {
+				rolls++
+			}
+			if rolls > 3 {
+				t.Fatalf("game %d had a turn of more than 3 rolls", game)
+			}
+			if turn.Ended {
+				rolls = 0
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// peekRecorder is a Peeker that rolls once, remembering the next die it saw.
+type peekRecorder struct {
+	seen []int
+}
+
+func (self *peekRecorder) NextAction(s score, nextDie int) action {
+	self.seen = append(self.seen, nextDie)
+	return self.nextAction(s)
+}
+
+func (self *peekRecorder) nextAction(s score) action {
+	if s.thisTurn > 0 {
+		return stay
+	}
+	return roll
+}
+
+func (self *peekRecorder) String() string { return "peek recorder" }
+
+func TestFaceMultipliersScaleTheRoll(t *testing.T) {
+	cfg := GameConfig{FaceMultipliers: map[int]int{6: 2}, MaxTurns: 1}
+	var turns []Turn
+	playFrom(cfg, &StayAtK{win}, &StayAtK{win}, 0, score{}, scripted(6, 3, 1), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	want := []Turn{{0, 12, 12, false}, {0, 3, 15, false}, {0, 1, 0, true}}
+	if !slices.Equal(turns, want) {
+		t.Errorf("rolling 6, 3, 1 with 6s doubled recorded %+v, want %+v", turns, want)
+	}
+
+	cfg.Peek = true
+	peeker := &peekRecorder{}
+	playFrom(cfg, peeker, &StayAtK{win}, 0, score{}, scripted(6, 2), nil)
+	if len(peeker.seen) == 0 || peeker.seen[0] != 12 {
+		t.Errorf("peeking at a doubled 6 saw %v, want 12", peeker.seen)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// A DieSource supplies the rolls of a game, one Roll at a time. Every
+// roll a game makes goes through a single DieSource, so a scripted one
+// fixes the whole game.
+type DieSource interface {
+	Roll() int
+}
+
+// Roll rolls d, so that any die is a DieSource.
+func (d die) Roll() int {
+	return d()
+}
+
+// FixedDie returns a DieSource that rolls each of rolls in turn. It panics
+// if asked for more rolls than there are.
+func FixedDie(rolls []int) DieSource {
+	next := 0
+	return die(func() int {
+		if next == len(rolls) {
+			panic(fmt.Sprintf("FixedDie: all %d rolls used", len(rolls)))
+		}
+		next++
+		return rolls[next-1]
+	})
+}
+
+// RandomDie returns a fair die with cfg's number of faces that rolls from a
+// source seeded with seed.
+func (cfg GameConfig) RandomDie(seed int64) DieSource {
+	return cfg.die(rand.New(rand.NewSource(seed)))
+}
+
+// PlayGame simulates a Pig game by the rules of cfg between a and b, in
+// which player first moves first and every roll comes from dice, and
+// returns how it ended. It passes every action taken to record unless it
+// is nil. It returns an error if cfg is invalid.
+func (cfg GameConfig) PlayGame(a, b Strategy, first int, dice DieSource, record func(Turn)) (GameDetail, error) {
+	if err := cfg.Validate(); err != nil {
+		return GameDetail{}, err
+	}
+	return playDetailed(cfg, a, b, first, dice.Roll, record), nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFixedDieScriptsAWholeGame(t *testing.T) {
+	cfg := GameConfig{Win: 10}
+	var turns []Turn
+	rolls := FixedDie([]int{6, 3, 4, 1, 2, 1, 4})
+	d, err := cfg.PlayGame(&StayAtK{5}, &StayAtK{5}, 0, rolls, func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Turn{
+		// Player 0 rolls 6 and stays: 6-0.
+		{0, 6, 6, false}, {0, 0, 6, true},
+		// Player 1 rolls 3 and 4 and stays: 6-7.
+		{1, 3, 3, false}, {1, 4, 7, false}, {1, 0, 7, true},
+		// Player 0 busts.
+		{0, 1, 0, true},
+		// Player 1 rolls 2, then busts.
+		{1, 2, 2, false}, {1, 1, 0, true},
+		// Player 0 rolls 4, reaching 10.
+		{0, 4, 4, false},
+	}
+	if !slices.Equal(turns, want) {
+		t.Errorf("the scripted game went\n%+v\nwant\n%+v", turns, want)
+	}
+	if want := (GameDetail{Winner: 0, Scores: [2]int{10, 7}, Turns: 5}); d != want {
+		t.Errorf("the scripted game ended %+v, want %+v", d, want)
+	}
+}
+
+func TestFixedDiePanicsWhenItRunsOut(t *testing.T) {
+	rolls := FixedDie([]int{3})
+	rolls.Roll()
+	defer func() {
+		if recover() == nil {
+			t.Error("rolling past the end of the script didn't panic")
+		}
+	}()
+	rolls.Roll()
+}
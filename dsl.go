@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseStrategyExpr builds the strategy described by expr, which is either a
+// LookupStrategy spec such as "stayat:20", a registered name called with
+// comma-separated arguments such as "adaptive(behind=30,ahead=15)", or a mix
+// of weighted expressions such as "mix(0.5:stayat:20, 0.5:random)". Mixes may
+// nest, and are seeded with 0.
+func ParseStrategyExpr(expr string) (Strategy, error) {
+	p := &exprParser{what: "strategy expression", s: expr}
+	strategy, err := p.expr()
+	if err == nil && p.skipSpace() < len(p.s) {
+		err = p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
+// An exprParser parses a strategy expression by recursive descent.
+type exprParser struct {
+	what string // What s describes, for errors
+	s    string
+	pos  int
+}
+
+func (self *exprParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s %q at offset %d: %s",
+		self.what, self.s, self.pos, fmt.Sprintf(format, args...))
+}
+
+// skipSpace advances past any spaces and returns the new position.
+func (self *exprParser) skipSpace() int {
+	for self.pos < len(self.s) && self.s[self.pos] == ' ' {
+		self.pos++
+	}
+	return self.pos
+}
+
+// next returns the next non-space byte without consuming it, or 0 at the
+// end of the expression.
+func (self *exprParser) next() byte {
+	if self.skipSpace() == len(self.s) {
+		return 0
+	}
+	return self.s[self.pos]
+}
+
+func (self *exprParser) expect(c byte) error {
+	if self.next() != c {
+		return self.errorf("expected %q", c)
+	}
+	self.pos++
+	return nil
+}
+
+// word consumes and returns the next name, number or name=value argument.
+func (self *exprParser) word() (string, error) {
+	start := self.skipSpace()
+	for self.pos < len(self.s) && !strings.ContainsRune(" :,(){}", rune(self.s[self.pos])) {
+		self.pos++
+	}
+	if self.pos == start {
+		return "", self.errorf("expected a name or value")
+	}
+	return self.s[start:self.pos], nil
+}
+
+func (self *exprParser) expr() (Strategy, error) {
+	start := self.skipSpace()
+	name, err := self.word()
+	if err != nil {
+		return nil, err
+	}
+	var args []string
+	if self.next() == '(' {
+		self.pos++
+		if name == "mix" {
+			return self.mixArgs()
+		}
+		for self.next() != ')' {
+			if len(args) > 0 {
+				if err := self.expect(','); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := self.word()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+		self.pos++
+	} else {
+		for self.next() == ':' {
+			self.pos++
+			arg, err := self.word()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+		}
+	}
+	strategy, err := buildStrategy(name, args)
+	if err != nil {
+		self.pos = start
+		return nil, self.errorf("%v", err)
+	}
+	return strategy, nil
+}
+
+// mixArgs parses the weighted members of a mix up to its closing parenthesis.
+func (self *exprParser) mixArgs() (Strategy, error) {
+	var members []Strategy
+	var weights []float64
+	for self.next() != ')' {
+		if len(members) > 0 {
+			if err := self.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		start := self.skipSpace()
+		w, err := self.word()
+		if err != nil {
+			return nil, err
+		}
+		weight, err := strconv.ParseFloat(w, 64)
+		if err != nil {
+			self.pos = start
+			return nil, self.errorf("bad weight %q", w)
+		}
+		if err := self.expect(':'); err != nil {
+			return nil, err
+		}
+		member, err := self.expr()
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+		weights = append(weights, weight)
+	}
+	self.pos++
+	m, err := NewMix(members, weights, 0)
+	if err != nil {
+		return nil, self.errorf("%v", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStrategyExprNestedMix(t *testing.T) {
+	s, err := ParseStrategyExpr("mix(0.25:stayat:20, 0.75:mix(1:adaptive(behind=30,ahead=15), 3:stayat:25))")
+	if err != nil {
+		t.Fatal(err)
+	}
+	outer, ok := s.(*Mix)
+	if !ok {
+		t.Fatalf("got %T, want *Mix", s)
+	}
+	if want := []float64{0.25, 0.75}; !reflect.DeepEqual(outer.weights, want) {
+		t.Errorf("outer weights %v, want %v", outer.weights, want)
+	}
+	if want := (&StayAtK{20}); !reflect.DeepEqual(outer.members[0], want) {
+		t.Errorf("outer first member %#v, want %#v", outer.members[0], want)
+	}
+	inner, ok := outer.members[1].(*Mix)
+	if !ok {
+		t.Fatalf("outer second member is %T, want *Mix", outer.members[1])
+	}
+	if want := []float64{1, 3}; !reflect.DeepEqual(inner.weights, want) {
+		t.Errorf("inner weights %v, want %v", inner.weights, want)
+	}
+	members := []Strategy{&Adaptive{30, 20, 15}, &StayAtK{25}}
+	if !reflect.DeepEqual(inner.members, members) {
+		t.Errorf("inner members %v, want %v", inner.members, members)
+	}
+}
+
+func TestParseStrategyExprRejectsBadMixes(t *testing.T) {
+	for _, expr := range []string{"mix(0.5:stayat:20", "mix(-1:stayat:20)", "mix()", "mix(0.5:nosuch)"} {
+		if _, err := ParseStrategyExpr(expr); err == nil {
+			t.Errorf("%q parsed, want an error", expr)
+		}
+	}
+}
+
+func TestParseStrategyExprRejectsOutOfRangeParameters(t *testing.T) {
+	for _, expr := range []string{"stayat:0", "stayat:k=1e9", "dice:dice=1e9", "dice:20,0", "weighted:p=2", "probthreshold:NaN"} {
+		if _, err := ParseStrategyExpr(expr); err == nil {
+			t.Errorf("%q parsed, want an error", expr)
+		}
+	}
+	for _, expr := range []string{"stayat:1", "stayat:100", "dice:dice=10", "mix(1:stayat:100, 1:weighted:p=1)"} {
+		if _, err := ParseStrategyExpr(expr); err != nil {
+			t.Errorf("%q: %v", expr, err)
+		}
+	}
+	// Every default must itself be in range.
+	for name, r := range registry {
+		for _, p := range r.params {
+			if !(p.Value >= p.Min && p.Value <= p.Max) {
+				t.Errorf("%s's default %s %g is not between %g and %g", name, p.Name, p.Value, p.Min, p.Max)
+			}
+		}
+	}
+}
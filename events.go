@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// A GameEventKind says what happened in a GameEvent.
+type GameEventKind int
+
+const (
+	EventRoll       GameEventKind = iota // Player rolled Die
+	EventStay                            // Player stayed
+	EventTurnChange                      // Player's turn began
+	EventGameOver                        // Winner won
+)
+
+// A GameEvent is one thing that happened during a game, as sent by
+// PlayEvents.
+type GameEvent struct {
+	Kind   GameEventKind
+	Player int // The player who acted, or whose turn began
+	Die    int // For EventRoll, the total rolled
+	Winner int // For EventGameOver, the winner (0 or 1)
+}
+
+// PlayEvents simulates a Pig game between s0 and s1, with rng choosing who
+// moves first and then rolling the dice, and sends what happens as it
+// happens: the first player's EventTurnChange, then an EventRoll or
+// EventStay for each action, an EventTurnChange whenever the turn passes,
+// and finally an EventGameOver. The channel is closed after the
+// EventGameOver. The consumer must drain the channel; use
+// PlayEventsContext to stop early.
+func PlayEvents(s0, s1 Strategy, rng *rand.Rand) <-chan GameEvent {
+	return PlayEventsContext(context.Background(), s0, s1, rng)
+}
+
+// PlayEventsContext is like PlayEvents, but stops sending and closes the
+// channel once ctx is done, so a consumer may stop reading by cancelling
+// ctx. The game is still played to the end, using up the same rolls of rng
+// either way.
+func PlayEventsContext(ctx context.Context, s0, s1 Strategy, rng *rand.Rand) <-chan GameEvent {
+	events := make(chan GameEvent)
+	send := func(e GameEvent) {
+		select {
+		case events <- e:
+		case <-ctx.Done():
+		}
+	}
+	go func() {
+		defer close(events)
+		first := rng.Intn(2)
+		send(GameEvent{Kind: EventTurnChange, Player: first})
+		winner := playGame(GameConfig{}, s0, s1, first, GameConfig{}.die(rng), func(t Turn) {
+			if ctx.Err() != nil {
+				return
+			}
+			if t.Die != 0 {
+				send(GameEvent{Kind: EventRoll, Player: t.Player, Die: t.Die})
+			} else {
+				send(GameEvent{Kind: EventStay, Player: t.Player})
+			}
+			if t.Ended {
+				send(GameEvent{Kind: EventTurnChange, Player: 1 - t.Player})
+			}
+		})
+		send(GameEvent{Kind: EventGameOver, Winner: winner})
+	}()
+	return events
+}
+
+// AnimateGame writes a play-by-play of events to w as they arrive, waiting
+// delay after each, until the channel is closed. It ends with the winner
+// and how many turns and rolls the game took.
+func AnimateGame(w io.Writer, events <-chan GameEvent, delay time.Duration) error {
+	turns, rolls := 0, 0
+	for e := range events {
+		var err error
+		switch e.Kind {
+		case EventTurnChange:
+			turns++
+			_, err = fmt.Fprintf(w, "Player %d's turn\n", e.Player)
+		case EventRoll:
+			rolls++
+			if e.Die == 1 {
+				_, err = fmt.Fprintf(w, "  rolls 1, losing the turn\n")
+			} else {
+				_, err = fmt.Fprintf(w, "  rolls %d\n", e.Die)
+			}
+		case EventStay:
+			_, err = fmt.Fprintf(w, "  stays\n")
+		case EventGameOver:
+			_, err = fmt.Fprintf(w, "Player %d wins after %d turns and %d rolls\n", e.Winner, turns, rolls)
+		}
+		if err != nil {
+			return err
+		}
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+	}
+	return nil
+}
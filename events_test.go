@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestPlayEventsReconstructTheGame(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		var banked [2]int
+		thisTurn, current, winner, over := 0, -1, -1, false
+		for e := range PlayEvents(&StayAtK{20}, &Random{}, rand.New(rand.NewSource(seed))) {
+			if over {
+				t.Fatalf("game %d sent %+v after the game was over", seed, e)
+			}
+			switch e.Kind {
+			case EventTurnChange:
+				current, thisTurn = e.Player, 0
+			case EventRoll:
+				if e.Player != current {
+					t.Fatalf("game %d: player %d rolled in player %d's turn", seed, e.Player, current)
+				}
+				thisTurn += e.Die
+				if e.Die == 1 {
+					thisTurn = 0
+				}
+				if banked[current]+thisTurn >= win {
+					winner = current
+				}
+			case EventStay:
+				banked[current] += thisTurn
+				thisTurn = 0
+			case EventGameOver:
+				over = true
+				if e.Winner != winner {
+					t.Errorf("game %d was won by player %d, but the events have player %d reaching %d first",
+						seed, e.Winner, winner, win)
+				}
+			}
+		}
+		if !over {
+			t.Errorf("game %d closed the channel without an EventGameOver", seed)
+		}
+	}
+}
+
+func TestAnimateGame(t *testing.T) {
+	events := make(chan GameEvent, 8)
+	for _, e := range []GameEvent{
+		{Kind: EventTurnChange, Player: 0},
+		{Kind: EventRoll, Player: 0, Die: 5},
+		{Kind: EventRoll, Player: 0, Die: 1},
+		{Kind: EventTurnChange, Player: 1},
+		{Kind: EventRoll, Player: 1, Die: 6},
+		{Kind: EventStay, Player: 1},
+		{Kind: EventGameOver, Winner: 1},
+	} {
+		events <- e
+	}
+	close(events)
+	var out strings.Builder
+	if err := AnimateGame(&out, events, 0); err != nil {
+		t.Fatal(err)
+	}
+	want := "Player 0's turn\n" +
+		"  rolls 5\n" +
+		"  rolls 1, losing the turn\n" +
+		"Player 1's turn\n" +
+		"  rolls 6\n" +
+		"  stays\n" +
+		"Player 1 wins after 2 turns and 3 rolls\n"
+	if out.String() != want {
+		t.Errorf("animated\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := AnimateGame(&out, PlayEvents(&StayAtK{20}, &StayAtK{25}, rand.New(rand.NewSource(1))), 0); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if !strings.HasSuffix(lines[0], "'s turn") || !strings.Contains(lines[len(lines)-1], " wins after ") {
+		t.Errorf("animating a game began with %q and ended with %q, want a turn and a winner", lines[0], lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// experimentParams maps each kind of experiment to the parameters it takes.
+var experimentParams = map[string][]string{
+	"roundrobin":  {"games", "seed"},
+	"elimination": {"games", "seed"},
+	"swiss":       {"games", "seed", "rounds"},
+}
+
+// RunExperiment parses and runs the tournament described by spec, which
+// names the kind of tournament, optionally followed by name=value parameters
+// in parentheses, and then the lineup in braces as comma-separated strategy
+// expressions, as accepted by ParseStrategyExpr. For example:
+//
+//	roundrobin(games=1000, seed=42){ stayat:20, random, optimal }
+//
+// The kinds are roundrobin, played as by Simulate; elimination, played as by
+// EliminationTournament with the lineup in seed order; and swiss, played as
+// by SwissTournament. Each takes games, the games per series, defaulting to
+// gamesPerSeries, and seed, where 0, the default, picks one from the clock;
+// swiss also takes rounds, defaulting to enough rounds for an elimination
+// tournament of the same lineup. For elimination and swiss, the standings
+// total the games each strategy won and lost over its matches.
+func RunExperiment(spec string) (SimResult, error) {
+	p := &exprParser{what: "experiment", s: spec}
+	start := p.skipSpace()
+	kind, err := p.word()
+	if err != nil {
+		return SimResult{}, err
+	}
+	params, ok := experimentParams[kind]
+	if !ok {
+		p.pos = start
+		return SimResult{}, p.errorf("unknown kind of experiment %q", kind)
+	}
+	values := map[string]int64{"games": gamesPerSeries}
+	if p.next() == '(' {
+		p.pos++
+		for n := 0; p.next() != ')'; n++ {
+			if n > 0 {
+				if err := p.expect(','); err != nil {
+					return SimResult{}, err
+				}
+			}
+			if err := p.param(kind, params, values); err != nil {
+				return SimResult{}, err
+			}
+		}
+		p.pos++
+	}
+	if err := p.expect('{'); err != nil {
+		return SimResult{}, err
+	}
+	var strategies []Strategy
+	for p.next() != '}' {
+		if len(strategies) > 0 {
+			if err := p.expect(','); err != nil {
+				return SimResult{}, err
+			}
+		}
+		strategy, err := p.expr()
+		if err != nil {
+			return SimResult{}, err
+		}
+		strategies = append(strategies, strategy)
+	}
+	p.pos++
+	if p.skipSpace() < len(p.s) {
+		return SimResult{}, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+
+	games, seed := int(values["games"]), resolveSeed(values["seed"])
+	switch kind {
+	case "elimination":
+		_, rounds := EliminationTournament(strategies, games, seed, nil)
+		return tournamentResult(seed, strategies, rounds), nil
+	case "swiss":
+		rounds, ok := values["rounds"]
+		if !ok {
+			for n := 1; n < len(strategies); n *= 2 {
+				rounds++
+			}
+		}
+		return tournamentResult(seed, strategies, SwissTournament(strategies, games, int(rounds), seed)), nil
+	}
+	return Simulate(strategies, games, seed), nil
+}
+
+// param parses a name=value parameter of an experiment of kind, which takes
+// params, into values.
+func (self *exprParser) param(kind string, params []string, values map[string]int64) error {
+	start := self.skipSpace()
+	w, err := self.word()
+	if err != nil {
+		return err
+	}
+	self.pos = start
+	name, value, ok := strings.Cut(w, "=")
+	if !ok {
+		return self.errorf("expected name=value, not %q", w)
+	}
+	known := false
+	for _, param := range params {
+		known = known || param == name
+	}
+	if !known {
+		return self.errorf("%s has no parameter %q", kind, name)
+	}
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || (v <= 0 && name != "seed") {
+		return self.errorf("bad value %q for %s", value, name)
+	}
+	values[name] = v
+	self.pos += len(w)
+	return nil
+}
+
+// tournamentResult returns the SimResult of a tournament between strategies
+// seeded with seed, which played rounds of matches.
+func tournamentResult(seed int64, strategies []Strategy, rounds [][]MatchResult) SimResult {
+	r := SimResult{Seed: seed}
+	for _, strategy := range strategies {
+		r.Standings = append(r.Standings, Standing{Strategy: strategy})
+	}
+	for _, matches := range rounds {
+		for _, m := range matches {
+			r.Games += m.WinsA + m.WinsB
+			r.Standings[m.I].Wins += m.WinsA
+			r.Standings[m.I].Losses += m.WinsB
+			r.Standings[m.J].Wins += m.WinsB
+			r.Standings[m.J].Losses += m.WinsA
+		}
+	}
+	return r
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestRunExperimentRoundRobin(t *testing.T) {
+	got, err := RunExperiment("roundrobin(games=200, seed=42){ stayat:20, random, optimal }")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Simulate([]Strategy{&StayAtK{20}, &Random{}, &Optimal{}}, 200, 42)
+	if got.Seed != want.Seed || got.Games != want.Games || len(got.Standings) != len(want.Standings) {
+		t.Fatalf("the experiment gave seed %d, %d games and %d standings, want %d, %d and %d",
+			got.Seed, got.Games, len(got.Standings), want.Seed, want.Games, len(want.Standings))
+	}
+	for i, st := range got.Standings {
+		w := want.Standings[i]
+		if st.Strategy.String() != w.Strategy.String() || st.Wins != w.Wins || st.Losses != w.Losses || st.Draws != w.Draws {
+			t.Errorf("standing %d is %v %d-%d, want %v %d-%d", i, st.Strategy, st.Wins, st.Losses, w.Strategy, w.Wins, w.Losses)
+		}
+	}
+	for _, bad := range []string{
+		"league(games=10){stayat:20, random}",
+		"roundrobin(rounds=3){stayat:20, random}",
+		"roundrobin(games=many){stayat:20, random}",
+		"roundrobin{stayat:20, nonsense}",
+		"roundrobin(games=10){stayat:20, random",
+	} {
+		if _, err := RunExperiment(bad); err == nil {
+			t.Errorf("running %q succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/mihasya/golangpigevolved
+
+go 1.24
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Limits on the work a request over HTTP may ask for.
+const (
+	maxHTTPGames      = 100000  // Games per series
+	maxHTTPStrategies = 32      // Strategies in a simulation
+	maxHTTPTotalGames = 1000000 // Games in a simulation, over every series
+	maxHTTPBody       = 1 << 20 // Bytes in a request body
+)
+
+// A simulateRequest is the body of a POST to /simulate.
+type simulateRequest struct {
+	Strategies []string `json:"strategies"` // Strategy expressions, as for ParseStrategyExpr
+	Games      int      `json:"games"`      // Games per series; 0 for gamesPerSeries
+	Seed       int64    `json:"seed"`       // 0 to pick one from the clock
+}
+
+// A gameRequest is the body of a POST to /game.
+type gameRequest struct {
+	A    string `json:"a"` // The strategy expression of the player who moves first
+	B    string `json:"b"`
+	Seed int64  `json:"seed"`
+}
+
+// Handler returns an HTTP handler serving the simulator as JSON:
+//
+//   - POST /simulate with a body like
+//     {"strategies": ["stayat:20", "optimal"], "games": 1000, "seed": 42}
+//     plays a round robin with Simulate and responds with its SimResult.
+//   - POST /game with a body like {"a": "stayat:20", "b": "random", "seed": 42}
+//     plays a game with RunGame and responds with its GameOutcome.
+//
+// A bad request gets status 400 and a body like {"error": "..."}. So does
+// one naming a strategy with a parameter out of its range, such as
+// "dice:dice=1e9", and one asking for more work than the limits above
+// allow: a simulation of more than maxHTTPStrategies strategies, of more
+// than maxHTTPGames games per series or maxHTTPTotalGames games in all, or
+// a body of more than maxHTTPBody bytes.
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /simulate", func(w http.ResponseWriter, r *http.Request) {
+		var req simulateRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxHTTPBody)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, fmt.Errorf("bad request body: %v", err))
+			return
+		}
+		if req.Games == 0 {
+			req.Games = gamesPerSeries
+		}
+		if req.Games < 0 || req.Games > maxHTTPGames {
+			httpError(w, fmt.Errorf("games must be between 1 and %d, not %d", maxHTTPGames, req.Games))
+			return
+		}
+		n := len(req.Strategies)
+		if n < 2 || n > maxHTTPStrategies {
+			httpError(w, fmt.Errorf("a simulation needs between 2 and %d strategies, not %d", maxHTTPStrategies, n))
+			return
+		}
+		if total := req.Games * n * (n - 1) / 2; total > maxHTTPTotalGames {
+			httpError(w, fmt.Errorf("a simulation may play at most %d games in all, not %d", maxHTTPTotalGames, total))
+			return
+		}
+		strategies := make([]Strategy, len(req.Strategies))
+		for i, expr := range req.Strategies {
+			s, err := ParseStrategyExpr(expr)
+			if err != nil {
+				httpError(w, err)
+				return
+			}
+			strategies[i] = s
+		}
+		writeJSON(w, Simulate(strategies, req.Games, req.Seed))
+	})
+	mux.HandleFunc("POST /game", func(w http.ResponseWriter, r *http.Request) {
+		var req gameRequest
+		r.Body = http.MaxBytesReader(w, r.Body, maxHTTPBody)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			httpError(w, fmt.Errorf("bad request body: %v", err))
+			return
+		}
+		a, err := ParseStrategyExpr(req.A)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		b, err := ParseStrategyExpr(req.B)
+		if err != nil {
+			httpError(w, err)
+			return
+		}
+		writeJSON(w, RunGame(a, b, resolveSeed(req.Seed)))
+	})
+	return mux
+}
+
+// writeJSON responds with v encoded as JSON.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// httpError responds to a bad request with err.
+func httpError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
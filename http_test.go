@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// post sends body to path on srv and decodes the JSON response into v,
+// returning the response's status.
+func post(t *testing.T, srv *httptest.Server, path, body string, v any) int {
+	t.Helper()
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("POST %s responded with Content-Type %q", path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("decoding the response to POST %s: %v", path, err)
+	}
+	return resp.StatusCode
+}
+
+func TestHandlerSimulate(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	var got struct {
+		Seed      int64 `json:"seed"`
+		Games     int   `json:"games"`
+		Standings []struct {
+			Strategy string  `json:"strategy"`
+			Wins     int     `json:"wins"`
+			Losses   int     `json:"losses"`
+			Draws    int     `json:"draws"`
+			WinRate  float64 `json:"winRate"`
+		} `json:"standings"`
+	}
+	body := `{"strategies": ["stayat:20", "random"], "games": 1000, "seed": 42}`
+	if status := post(t, srv, "/simulate", body, &got); status != http.StatusOK {
+		t.Fatalf("POST /simulate responded with status %d", status)
+	}
+	if got.Seed != 42 || got.Games != 1000 || len(got.Standings) != 2 {
+		t.Fatalf("POST /simulate responded with seed %d, %d games and %d standings, want 42, 1000 and 2",
+			got.Seed, got.Games, len(got.Standings))
+	}
+	want := Simulate([]Strategy{&StayAtK{20}, &Random{}}, 1000, 42)
+	for i, st := range got.Standings {
+		w := want.Standings[i]
+		if st.Strategy != w.Strategy.String() || st.Wins != w.Wins || st.Losses != w.Losses || st.Draws != w.Draws {
+			t.Errorf("standing %d is %+v, want %+v", i, st, w)
+		}
+		if math.Abs(st.WinRate-float64(st.Wins)/1000) > 1e-12 {
+			t.Errorf("standing %d has win rate %g with %d wins of 1000", i, st.WinRate, st.Wins)
+		}
+	}
+	if got.Standings[0].Wins < 900 {
+		t.Errorf("StayAtK{20} only won %d of 1000 games against Random", got.Standings[0].Wins)
+	}
+
+	for _, bad := range []string{
+		`{"strategies": ["stayat:20"]}`,
+		`{"strategies": ["stayat:20", "nonsense"]}`,
+		`{"strategies": ["stayat:20", "stayat:k=1e9"]}`,
+		`{"strategies": ["stayat:20", "random"], "games": 1000000}`,
+		`not json`,
+	} {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if status := post(t, srv, "/simulate", bad, &e); status != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("POST /simulate of %s responded with status %d and error %q, want 400 and a message", bad, status, e.Error)
+		}
+	}
+}
+
+func TestHandlerGame(t *testing.T) {
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	var got GameOutcome
+	if status := post(t, srv, "/game", `{"a": "stayat:20", "b": "stayat:25", "seed": 7}`, &got); status != http.StatusOK {
+		t.Fatalf("POST /game responded with status %d", status)
+	}
+	if want := RunGame(&StayAtK{20}, &StayAtK{25}, 7); got.Winner != want.Winner ||
+		got.Scores != want.Scores || got.Turns != want.Turns || len(got.Transcript) != len(want.Transcript) {
+		t.Errorf("POST /game responded with %+v, want %+v", got, want)
+	}
+
+	// Out-of-range parameters are turned away before any game is played.
+	for _, bad := range []string{
+		`{"a": "dice:dice=1e9", "b": "random"}`,
+		`{"a": "stayat:20", "b": "annealing:cooling=5"}`,
+	} {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if status := post(t, srv, "/game", bad, &e); status != http.StatusBadRequest || e.Error == "" {
+			t.Errorf("POST /game of %s responded with status %d and error %q, want 400 and a message", bad, status, e.Error)
+		}
+	}
+}
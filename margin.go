@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"sync"
+)
+
+// A marginTable is like a winTable, but holds the expected final margin,
+// the current player's final score less their opponent's, if both players
+// play to maximize their own margin.
+type marginTable struct {
+	win int
+	m   []float64
+}
+
+// newMarginTable computes the margin table for a game to win by value
+// iteration, in the same order as newWinTable.
+func newMarginTable(win int) *marginTable {
+	t := &marginTable{win, make([]float64, win*win*win)}
+	for total := 2 * (win - 1); total >= 0; total-- {
+		for i := 0; i < win; i++ {
+			if j := total - i; j >= i && j < win {
+				t.converge(i, j)
+			}
+		}
+	}
+	return t
+}
+
+// converge iterates the states where the banked scores are i and j, in
+// either order, until no margin changes by more than convergence.
+func (self *marginTable) converge(i, j int) {
+	for {
+		delta := 0.0
+		for _, banked := range [][2]int{{i, j}, {j, i}} {
+			for k := self.win - banked[0] - 1; k >= 0; k-- {
+				s := score{banked[0], banked[1], k}
+				m := math.Max(self.rollMargin(s), self.stayMargin(s))
+				delta = math.Max(delta, math.Abs(m-self.m[self.index(s)]))
+				self.m[self.index(s)] = m
+			}
+		}
+		if delta < convergence {
+			return
+		}
+	}
+}
+
+func (self *marginTable) index(s score) int {
+	return (s.player*self.win+s.opponent)*self.win + s.thisTurn
+}
+
+// at returns the current player's expected final margin from s.
+func (self *marginTable) at(s score) float64 {
+	if s.player+s.thisTurn >= self.win {
+		return float64(s.player + s.thisTurn - s.opponent)
+	}
+	return self.m[self.index(s)]
+}
+
+// stayMargin returns the current player's expected final margin if they
+// stay at s.
+func (self *marginTable) stayMargin(s score) float64 {
+	if s.player+s.thisTurn >= self.win {
+		return self.at(s)
+	}
+	return -self.at(score{s.opponent, s.player + s.thisTurn, 0})
+}
+
+// rollMargin returns the current player's expected final margin if they
+// roll at s.
+func (self *marginTable) rollMargin(s score) float64 {
+	m := -self.at(score{s.opponent, s.player, 0})
+	for outcome := 2; outcome <= 6; outcome++ {
+		m += self.at(score{s.player, s.opponent, s.thisTurn + outcome})
+	}
+	return m / 6
+}
+
+var maxMargin struct {
+	once  sync.Once
+	table *marginTable
+}
+
+// maxMarginTable returns the margin table for a game to win, computing it
+// the first time it is needed.
+func maxMarginTable() *marginTable {
+	maxMargin.once.Do(func() {
+		maxMargin.table = newMarginTable(win)
+	})
+	return maxMargin.table
+}
+
+// MaxMargin chooses whichever action maximizes its expected final margin,
+// rather than its probability of winning, assuming its opponent does the
+// same. On average it finishes further ahead of its opponents than Optimal
+// does, though it wins less often.
+type MaxMargin struct{}
+
+func (self *MaxMargin) nextAction(s score) action {
+	t := maxMarginTable()
+	if t.rollMargin(s) > t.stayMargin(s) {
+		return roll
+	}
+	return stay
+}
+
+func (self *MaxMargin) String() string {
+	return "MaxMargin"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RunMatchupsCSV reads matchups from in as CSV rows of the form
+//
+//	strategyA,strategyB,games,seed
+//
+// with the strategies specified as for LookupStrategy, and plays each with
+// CompareStrategies. It writes a header and then one row per matchup to out,
+// the matchup's fields followed by winsA, winsB, draws and error. A row
+// that can't be played has empty results and says why in its error column,
+// and any fields past its fourth are left out; the other rows are still
+// played. It returns an error only if in can't be read or out can't be
+// written.
+func RunMatchupsCSV(in io.Reader, out io.Writer) error {
+	r := csv.NewReader(in)
+	r.FieldsPerRecord = -1
+	w := csv.NewWriter(out)
+	w.Write([]string{"strategyA", "strategyB", "games", "seed", "winsA", "winsB", "draws", "error"})
+	for {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return err
+		}
+		if err == nil {
+			row, err = playMatchupRow(row)
+		}
+		if err != nil {
+			// Keep just the matchup's four fields, padded or trimmed, so
+			// that the error lines up with its column.
+			fields := make([]string, 4)
+			copy(fields, row)
+			row = append(fields, "", "", "", err.Error())
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// playMatchupRow plays the matchup in row, for RunMatchupsCSV, and returns
+// row with its results appended.
+func playMatchupRow(row []string) ([]string, error) {
+	if len(row) != 4 {
+		return row, fmt.Errorf("want 4 fields, got %d", len(row))
+	}
+	a, err := LookupStrategy(row[0])
+	if err != nil {
+		return row, err
+	}
+	b, err := LookupStrategy(row[1])
+	if err != nil {
+		return row, err
+	}
+	games, err := strconv.Atoi(row[2])
+	if err != nil || games < 1 {
+		return row, fmt.Errorf("bad games %q", row[2])
+	}
+	seed, err := strconv.ParseInt(row[3], 10, 64)
+	if err != nil {
+		return row, fmt.Errorf("bad seed %q", row[3])
+	}
+	c := CompareStrategies(a, b, games, seed)
+	return append(row, strconv.Itoa(c.WinsA), strconv.Itoa(c.WinsB), strconv.Itoa(c.Draws), ""), nil
+}
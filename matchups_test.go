@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRunMatchupsCSV(t *testing.T) {
+	in := "stayat:20,stayat:25,500,1\n" +
+		"stayat:20,nonsense,500,1\n" +
+		"stayat:20,random,many,1\n" +
+		"stayat:20,stayat:25\n" +
+		"stayat:20,stayat:25,500,1,extra,fields\n" +
+		"stayat:25,stayat:20,400,2\n"
+	var out strings.Builder
+	if err := RunMatchupsCSV(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := []string{"strategyA", "strategyB", "games", "seed", "winsA", "winsB", "draws", "error"}
+	if strings.Join(rows[0], ",") != strings.Join(header, ",") {
+		t.Errorf("the header is %q, want %q", rows[0], header)
+	}
+	if len(rows) != 7 {
+		t.Fatalf("wrote %d rows for 6 matchups, want a header and one per matchup", len(rows))
+	}
+	for i, want := range []struct {
+		c     Comparison
+		error bool
+	}{
+		{CompareStrategies(&StayAtK{20}, &StayAtK{25}, 500, 1), false},
+		{error: true},
+		{error: true},
+		{error: true},
+		{error: true},
+		{CompareStrategies(&StayAtK{25}, &StayAtK{20}, 400, 2), false},
+	} {
+		row := rows[i+1]
+		if len(row) != len(header) {
+			t.Errorf("row %d is %q, want %d fields", i+1, row, len(header))
+			continue
+		}
+		if want.error {
+			if row[7] == "" || row[4] != "" || row[5] != "" || row[6] != "" {
+				t.Errorf("row %d is %q, want empty results and an error", i+1, row)
+			}
+			continue
+		}
+		got := []string{row[4], row[5], row[6], row[7]}
+		results := []string{strconv.Itoa(want.c.WinsA), strconv.Itoa(want.c.WinsB), strconv.Itoa(want.c.Draws), ""}
+		if strings.Join(got, ",") != strings.Join(results, ",") {
+			t.Errorf("row %d has results %q, want %q", i+1, got, results)
+		}
+	}
+	if !strings.Contains(rows[2][7], "nonsense") {
+		t.Errorf("the error for an unknown strategy, %q, doesn't name it", rows[2][7])
+	}
+}
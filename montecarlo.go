@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// An OpponentTurn is what a player can observe of one of its opponent's
+// turns: the score it started from, from the opponent's point of view, and
+// how many points it banked, which is 0 if it rolled a 1.
+type OpponentTurn struct {
+	Start  GameState
+	Banked int
+}
+
+// An OpponentModel returns a strategy that plays like an opponent who has
+// been seen to play turns, for MonteCarlo to roll out against. It must not
+// return a strategy that is in use elsewhere, since MonteCarlo will play it.
+type OpponentModel func(turns []OpponentTurn) Strategy
+
+// MonteCarlo chooses each action by trying both: for each, it plays out
+// rollouts games from the result, with policy playing for itself and the
+// strategy returned by model for its opponent, and takes whichever action
+// won more of them, except that it always rolls at the start of a turn,
+// since staying then gains nothing. Ties go to staying. The two actions'
+// chances are usually close, so it takes thousands of rollouts to play
+// well. It watches its opponent's turns across the games of a series, until
+// Reset, to pass to model. Like Random, it is not safe for concurrent use;
+// concurrent series use clones.
+type MonteCarlo struct {
+	rollouts int
+	policy   Strategy
+	model    OpponentModel
+	rng      *rand.Rand
+
+	observed []OpponentTurn
+	// The opponent's banked score at this player's last decision, or -1 if
+	// it has not decided yet this game.
+	lastOpponent int
+}
+
+// NewMonteCarlo returns a MonteCarlo that plays rollouts games per action
+// with policy, drawing from its own source seeded with seed. If model is
+// nil, it assumes its opponent plays like policy.
+func NewMonteCarlo(rollouts int, policy Strategy, model OpponentModel, seed int64) *MonteCarlo {
+	if model == nil {
+		model = func([]OpponentTurn) Strategy {
+			return clone(policy)
+		}
+	}
+	return &MonteCarlo{
+		rollouts:     rollouts,
+		policy:       policy,
+		model:        model,
+		rng:          rand.New(rand.NewSource(seed)),
+		lastOpponent: -1,
+	}
+}
+
+// observe records the opponent's last turn, if this player has decided
+// since it began.
+func (self *MonteCarlo) observe(g GameState) {
+	if g.thisTurn != 0 {
+		return
+	}
+	if self.lastOpponent >= 0 {
+		self.observed = append(self.observed, OpponentTurn{
+			Start:  GameState{score{self.lastOpponent, g.player, 0}, g.Turn - 1},
+			Banked: g.opponent - self.lastOpponent,
+		})
+	}
+	self.lastOpponent = g.opponent
+}
+
+// rollWins returns how many more of rollouts games this player wins after
+// rolling at g, against opponent, than after staying. Each game after
+// rolling sees the same rolls as one after staying, so that the difference
+// comes from the action rather than the dice.
+func (self *MonteCarlo) rollWins(g GameState, opponent Strategy) int {
+	var rolls []int
+	next := 0
+	d := die(func() int {
+		if next == len(rolls) {
+			rolls = append(rolls, self.rng.Intn(6)+1)
+		}
+		next++
+		return rolls[next-1]
+	})
+	margin := 0
+	for i := 0; i < self.rollouts; i++ {
+		rolls = rolls[:0]
+		for _, a := range []action{roll, stay} {
+			next = 0
+			s, turnIsOver := a(g.score, d)
+			first := 0
+			if turnIsOver {
+				first = 1
+			}
+			if playFrom(GameConfig{}, self.policy, opponent, first, s, d, nil).Winner == 0 {
+				if isRoll(a) {
+					margin++
+				} else {
+					margin--
+				}
+			}
+		}
+	}
+	return margin
+}
+
+func (self *MonteCarlo) NextActionAt(g GameState) action {
+	self.observe(g)
+	if g.thisTurn == 0 || self.rollWins(g, self.model(self.observed)) > 0 {
+		return roll
+	}
+	return stay
+}
+
+func (self *MonteCarlo) nextAction(s score) action {
+	return self.NextActionAt(GameState{score: s, Turn: 1})
+}
+
+// SetStartingPlayer starts watching a new game.
+func (self *MonteCarlo) SetStartingPlayer(first bool) {
+	self.lastOpponent = -1
+}
+
+// Reset forgets every turn of its opponent's it has seen.
+func (self *MonteCarlo) Reset() {
+	self.observed = nil
+	self.lastOpponent = -1
+}
+
+func (self *MonteCarlo) Seed(seed int64) {
+	self.rng.Seed(seed)
+}
+
+// Clone returns a MonteCarlo that has seen nothing, with its own source
+// seeded from self's.
+func (self *MonteCarlo) Clone() Strategy {
+	return NewMonteCarlo(self.rollouts, clone(self.policy), self.model, self.rng.Int63())
+}
+
+func (self *MonteCarlo) String() string {
+	return fmt.Sprintf("MonteCarlo(%d, %v)", self.rollouts, self.policy)
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestMonteCarloGainsFromATrueOpponentModel(t *testing.T) {
+	if testing.Short() {
+		t.Skip("rolling out every decision is slow")
+	}
+	// StayAtK{8} banks far more often than the StayAtK{20} the fixed model
+	// assumes, which changes when it pays to keep rolling.
+	opponent := &StayAtK{8}
+	truth := NewMonteCarlo(50, &StayAtK{20}, func([]OpponentTurn) Strategy { return &StayAtK{8} }, 1)
+	fixed := NewMonteCarlo(50, &StayAtK{20}, func([]OpponentTurn) Strategy { return &StayAtK{20} }, 1)
+	modelled := seededSeries(truth, opponent, 1000, 1)
+	assumed := seededSeries(fixed, opponent, 1000, 1)
+	if modelled.WinsA <= assumed.WinsA+40 {
+		t.Errorf("rolling out against StayAtK{8} won %d of 1000 games against it, and against StayAtK{20} %d, want clearly more",
+			modelled.WinsA, assumed.WinsA)
+	}
+}
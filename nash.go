@@ -0,0 +1,56 @@
+package main
+
+// The exploitability at which NashMixture stops, and the most iterations of
+// fictitious play it runs before giving up on reaching it.
+const (
+	nashTolerance     = 1e-4
+	nashMaxIterations = 1000000
+)
+
+// NashMixture returns approximate equilibrium weights, summing to 1, for the
+// symmetric zero-sum game whose payoff matrix, as from PayoffMatrix, is
+// payoff: a mixture of the strategies that no single strategy does much
+// better than 0.5 against.
+//
+// It uses fictitious play. Starting from the first strategy, each iteration
+// adds the strategy that does best against the mixture of those added so
+// far, and the mixture is how often each was added. It stops once no
+// strategy's payoff against the mixture is more than nashTolerance above
+// the mixture's payoff against itself, or after nashMaxIterations.
+func NashMixture(payoff [][]float64) []float64 {
+	n := len(payoff)
+	if n == 0 {
+		return nil
+	}
+	counts := make([]float64, n)
+	// totals[i] is strategy i's summed payoff against every strategy added.
+	totals := make([]float64, n)
+	add := func(k int) {
+		counts[k]++
+		for i := range totals {
+			totals[i] += payoff[i][k]
+		}
+	}
+	add(0)
+	for t := 1; t < nashMaxIterations; t++ {
+		best, self := 0, 0.0
+		for i := range totals {
+			if totals[i] > totals[best] {
+				best = i
+			}
+			self += counts[i] * totals[i]
+		}
+		if totals[best]/float64(t)-self/float64(t*t) <= nashTolerance {
+			break
+		}
+		add(best)
+	}
+	total := 0.0
+	for _, c := range counts {
+		total += c
+	}
+	for i := range counts {
+		counts[i] /= total
+	}
+	return counts
+}
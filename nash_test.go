@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNashMixtureOfRockPaperScissors(t *testing.T) {
+	// Rock loses to paper, paper to scissors and scissors to rock.
+	payoff := [][]float64{
+		{0.5, 0, 1},
+		{1, 0.5, 0},
+		{0, 1, 0.5},
+	}
+	weights := NashMixture(payoff)
+	sum := 0.0
+	for i, w := range weights {
+		if math.Abs(w-1.0/3) > 0.05 {
+			t.Errorf("strategy %d has weight %.3f, want about 1/3", i, w)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("the weights %v add up to %g, want 1", weights, sum)
+	}
+}
+
+func TestNashMixtureOfADominantStrategy(t *testing.T) {
+	payoff := [][]float64{
+		{0.5, 0.3},
+		{0.7, 0.5},
+	}
+	if weights := NashMixture(payoff); weights[1] < 0.95 {
+		t.Errorf("the dominant strategy has weight %.3f, want nearly all of it", weights[1])
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+)
+
+// The tolerance at which value iteration of the win-probability table stops.
+const convergence = 1e-9
+
+// A winTable holds, for every non-terminal score in a game to win, the
+// probability that the current player wins if both players play optimally.
+type winTable struct {
+	win int
+	p   []float64
+}
+
+// newWinTable computes the win-probability table for a game to win by value
+// iteration. A state only depends on states with a higher banked total or
+// on states with the same two banked scores, so each pair of banked scores
+// is iterated to convergence in turn, from the highest total down.
+func newWinTable(win int) *winTable {
+	t := &winTable{win, make([]float64, win*win*win)}
+	for total := 2 * (win - 1); total >= 0; total-- {
+		for i := 0; i < win; i++ {
+			if j := total - i; j >= i && j < win {
+				t.converge(i, j)
+			}
+		}
+	}
+	return t
+}
+
+// converge iterates the states where the banked scores are i and j, in
+// either order, until no probability changes by more than convergence.
+func (self *winTable) converge(i, j int) {
+	for {
+		delta := 0.0
+		for _, banked := range [][2]int{{i, j}, {j, i}} {
+			for k := self.win - banked[0] - 1; k >= 0; k-- {
+				s := score{banked[0], banked[1], k}
+				p := math.Max(self.rollProbability(s), self.stayProbability(s))
+				delta = math.Max(delta, math.Abs(p-self.p[self.index(s)]))
+				self.p[self.index(s)] = p
+			}
+		}
+		if delta < convergence {
+			return
+		}
+	}
+}
+
+// ReachableStates returns every non-terminal score that can arise in a game
+// to win: those with 0 <= player < win, 0 <= opponent < win, and either
+// thisTurn == 0 or 2 <= thisTurn < win-player. A turn total of 1 can't
+// arise, since rolling a 1 ends the turn with nothing. The states are in
+// order of player, then opponent, then thisTurn.
+func ReachableStates(win int) []score {
+	var states []score
+	for player := 0; player < win; player++ {
+		for opponent := 0; opponent < win; opponent++ {
+			states = append(states, score{player, opponent, 0})
+			for thisTurn := 2; player+thisTurn < win; thisTurn++ {
+				states = append(states, score{player, opponent, thisTurn})
+			}
+		}
+	}
+	return states
+}
+
+func (self *winTable) index(s score) int {
+	return (s.player*self.win+s.opponent)*self.win + s.thisTurn
+}
+
+// at returns the probability that the current player wins from s.
+func (self *winTable) at(s score) float64 {
+	if s.player+s.thisTurn >= self.win {
+		return 1
+	}
+	return self.p[self.index(s)]
+}
+
+// stayProbability returns the current player's win probability if they stay
+// at s and play optimally afterwards.
+func (self *winTable) stayProbability(s score) float64 {
+	if s.player+s.thisTurn >= self.win {
+		return 1
+	}
+	return 1 - self.at(score{s.opponent, s.player + s.thisTurn, 0})
+}
+
+// rollProbability returns the current player's win probability if they roll
+// at s and play optimally afterwards.
+func (self *winTable) rollProbability(s score) float64 {
+	p := 1 - self.at(score{s.opponent, s.player, 0})
+	for outcome := 2; outcome <= 6; outcome++ {
+		p += self.at(score{s.player, s.opponent, s.thisTurn + outcome})
+	}
+	return p / 6
+}
+
+var optimal struct {
+	once  sync.Once
+	table *winTable
+}
+
+// optimalTable returns the win-probability table for a game to win,
+// computing it the first time it is needed.
+func optimalTable() *winTable {
+	optimal.once.Do(func() {
+		optimal.table = newWinTable(win)
+	})
+	return optimal.table
+}
+
+// WinProbability returns the probability that the current player wins from s
+// if both players play optimally.
+func WinProbability(s score) float64 {
+	return optimalTable().at(s)
+}
+
+// GameValue returns the probability that the first player wins a game to
+// win if both players play optimally. For the standard game it uses the
+// cached table; for any other it computes one, which takes a while.
+func GameValue(win int) float64 {
+	t := optimalTable()
+	if win != t.win {
+		t = newWinTable(win)
+	}
+	return t.at(score{})
+}
+
+// Optimal chooses whichever action maximizes its probability of winning.
+type Optimal struct{}
+
+func (self *Optimal) nextAction(s score) action {
+	t := optimalTable()
+	if t.rollProbability(s) > t.stayProbability(s) {
+		return roll
+	}
+	return stay
+}
+
+func (self *Optimal) String() string {
+	return "Optimal"
+}
+
+// PeekOptimal plays optimally when it can see the next die: it rolls unless
+// the next die is a 1. Rolling any other value can't hurt, since it could
+// stay straight after with more than it has now, and rolling a 1 only loses
+// the turn total, which staying banks. When it can't peek, it plays like
+// Optimal.
+type PeekOptimal struct{}
+
+func (self *PeekOptimal) NextAction(s score, nextDie int) action {
+	if nextDie == 1 {
+		return stay
+	}
+	return roll
+}
+
+func (self *PeekOptimal) nextAction(s score) action {
+	return (&Optimal{}).nextAction(s)
+}
+
+func (self *PeekOptimal) String() string {
+	return "PeekOptimal"
+}
+
+// ExplainDecision says which action Optimal takes at s and why, giving its
+// probability of winning if it rolls and if it stays, e.g.
+// "Roll: win prob if roll 0.62 vs if stay 0.58".
+func (self *Optimal) ExplainDecision(s score) string {
+	t := optimalTable()
+	r, st := t.rollProbability(s), t.stayProbability(s)
+	if r > st {
+		return fmt.Sprintf("Roll: win prob if roll %.2f vs if stay %.2f", r, st)
+	}
+	return fmt.Sprintf("Stay: win prob if stay %.2f vs if roll %.2f", st, r)
+}
+
+// ProbThreshold rolls only if rolling raises its probability of winning by
+// more than epsilon over staying. With epsilon 0 it plays like Optimal.
+type ProbThreshold struct {
+	epsilon float64
+}
+
+func (self *ProbThreshold) nextAction(s score) action {
+	t := optimalTable()
+	if t.rollProbability(s)-t.stayProbability(s) > self.epsilon {
+		return roll
+	}
+	return stay
+}
+
+func (self *ProbThreshold) String() string {
+	return fmt.Sprintf("ProbThreshold(%g)", self.epsilon)
+}
+
+// actionProbability returns the current player's win probability if they
+// take a at s and play optimally afterwards. An action's outcome depends only
+// on s and the dice it rolls, so a is replayed against every sequence of
+// rolls it could see, each weighted by its probability.
+func actionProbability(a action, s score) float64 {
+	t := optimalTable()
+	p := 0.0
+	type sequence struct {
+		rolls  []int
+		weight float64
+	}
+	pending := []sequence{{nil, 1}}
+	for len(pending) > 0 {
+		seq := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		used, short := 0, false
+		result, turnIsOver := a(s, func() int {
+			if used == len(seq.rolls) {
+				short = true
+				return 1
+			}
+			used++
+			return seq.rolls[used-1]
+		})
+		if short {
+			for outcome := 1; outcome <= 6; outcome++ {
+				rolls := append(append([]int(nil), seq.rolls...), outcome)
+				pending = append(pending, sequence{rolls, seq.weight / 6})
+			}
+			continue
+		}
+		switch {
+		case turnIsOver && result.opponent >= t.win:
+			// Staying banked enough to win.
+			p += seq.weight
+		case turnIsOver:
+			p += seq.weight * (1 - t.at(result))
+		default:
+			p += seq.weight * t.at(result)
+		}
+	}
+	return p
+}
+
+// DecisionLoss returns how much win probability choosing chosen at s gives
+// up compared to the optimal action. It panics if either banked score in s
+// has already won, since the game is over then.
+func DecisionLoss(s score, chosen action) float64 {
+	if s.player >= win || s.opponent >= win {
+		panic(fmt.Sprintf("DecisionLoss: the game at %+v is over", s))
+	}
+	return WinProbability(s) - actionProbability(chosen, s)
+}
+
+// BreakevenTurnScore returns the lowest thisTurn at which staying is at
+// least as likely to win as rolling, when the banked scores are playerScore
+// and opponentScore. If rolling is always better short of winning, it
+// returns the points needed to win.
+func BreakevenTurnScore(playerScore, opponentScore int) int {
+	t := optimalTable()
+	for k := 0; playerScore+k < win; k++ {
+		s := score{playerScore, opponentScore, k}
+		if t.stayProbability(s) >= t.rollProbability(s) {
+			return k
+		}
+	}
+	return win - playerScore
+}
+
+// RollTrajectory returns, for each n from 0 to maxRolls, the current
+// player's win probability at s if they roll n more times, unless the turn
+// ends or they win first, then stay and play optimally afterwards. The n
+// with the highest probability is the best number of rolls to commit to.
+func RollTrajectory(s score, maxRolls int) []float64 {
+	t := optimalTable()
+	trajectory := make([]float64, maxRolls+1)
+	// pending[k] is the probability of having thisTurn k, short of winning;
+	// settled is the win probability already decided by a bust or a win.
+	pending := make([]float64, max(t.win, s.thisTurn+1))
+	pending[s.thisTurn] = 1
+	settled := 0.0
+	for n := 0; n <= maxRolls; n++ {
+		p := settled
+		for thisTurn, q := range pending {
+			if q > 0 {
+				p += q * t.stayProbability(score{s.player, s.opponent, thisTurn})
+			}
+		}
+		trajectory[n] = p
+		next := make([]float64, len(pending))
+		for thisTurn, q := range pending {
+			if q == 0 {
+				continue
+			}
+			settled += q / 6 * (1 - t.at(score{s.opponent, s.player, 0}))
+			for outcome := 2; outcome <= 6; outcome++ {
+				if s.player+thisTurn+outcome >= t.win {
+					settled += q / 6
+				} else {
+					next[thisTurn+outcome] += q / 6
+				}
+			}
+		}
+		pending = next
+	}
+	return trajectory
+}
+
+// ExportDecisionGrid writes Optimal's decisions when its opponent has banked
+// opponentScore as CSV: a header row of thisTurn values, then one row for
+// each banked score of its own, starting with that score, giving "R" where
+// it rolls and "S" where it stays. Cells where banking would already win
+// are left empty.
+func ExportDecisionGrid(w io.Writer, opponentScore int) error {
+	if opponentScore < 0 || opponentScore >= win {
+		return fmt.Errorf("opponent score %d is not between 0 and %d", opponentScore, win-1)
+	}
+	t := optimalTable()
+	cw := csv.NewWriter(w)
+	row := []string{"player"}
+	for thisTurn := 0; thisTurn < win; thisTurn++ {
+		row = append(row, strconv.Itoa(thisTurn))
+	}
+	cw.Write(row)
+	for player := 0; player < win; player++ {
+		row = append(row[:0], strconv.Itoa(player))
+		for thisTurn := 0; thisTurn < win; thisTurn++ {
+			s := score{player, opponentScore, thisTurn}
+			switch {
+			case player+thisTurn >= win:
+				row = append(row, "")
+			case t.rollProbability(s) > t.stayProbability(s):
+				row = append(row, "R")
+			default:
+				row = append(row, "S")
+			}
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestProbThresholdZeroPlaysLikeOptimal(t *testing.T) {
+	optimal, threshold := &Optimal{}, &ProbThreshold{0}
+	for _, s := range ReachableStates(win) {
+		if isRoll(optimal.nextAction(s)) != isRoll(threshold.nextAction(s)) {
+			t.Fatalf("at %+v ProbThreshold(0) and Optimal disagree", s)
+		}
+	}
+}
+
+func TestProbThresholdLargeEpsilonIsConservative(t *testing.T) {
+	optimal, threshold := &Optimal{}, &ProbThreshold{0.5}
+	optimalRolls, thresholdRolls := 0, 0
+	for _, s := range ReachableStates(win) {
+		if isRoll(optimal.nextAction(s)) {
+			optimalRolls++
+		}
+		if isRoll(threshold.nextAction(s)) {
+			thresholdRolls++
+		}
+	}
+	if thresholdRolls*20 > optimalRolls {
+		t.Errorf("ProbThreshold(0.5) rolls in %d states, Optimal in %d; want under 5%% as many", thresholdRolls, optimalRolls)
+	}
+}
+
+func TestDecisionLoss(t *testing.T) {
+	for _, s := range []score{{0, 0, 0}, {50, 60, 12}, {80, 90, 15}} {
+		best := (&Optimal{}).nextAction(s)
+		if loss := DecisionLoss(s, best); math.Abs(loss) > 1e-9 {
+			t.Errorf("Optimal's action at %+v loses %g, want 0", s, loss)
+		}
+	}
+	if loss := DecisionLoss(score{0, 0, 40}, roll); loss <= 0.01 {
+		t.Errorf("rolling on 40 at the start loses %g, want clearly more than 0", loss)
+	}
+	if loss := DecisionLoss(score{20, 20, 0}, stay); loss <= 0.01 {
+		t.Errorf("staying on nothing loses %g, want clearly more than 0", loss)
+	}
+	if loss := DecisionLoss(score{95, 50, 10}, stay); math.Abs(loss) > 1e-9 {
+		t.Errorf("staying to win loses %g, want 0", loss)
+	}
+}
+
+func TestDecisionLossRejectsFinishedGames(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("DecisionLoss of a finished game didn't panic")
+		}
+	}()
+	DecisionLoss(score{win, 50, 0}, stay)
+}
+
+func TestOptimalTableIsBuiltOnceUnderConcurrentUse(t *testing.T) {
+	// Forget any table an earlier test built, so that the goroutines race to
+	// build it. Run with -race to check they share it safely.
+	optimal.once, optimal.table = sync.Once{}, nil
+	const goroutines = 32
+	tables := make([]*winTable, goroutines)
+	var wg sync.WaitGroup
+	for i := range tables {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			(&Optimal{}).nextAction(score{i, 2 * i, i % 7})
+			tables[i] = optimalTable()
+		}()
+	}
+	wg.Wait()
+	for i, table := range tables {
+		if table != tables[0] {
+			t.Fatalf("goroutine %d got a different table from goroutine 0", i)
+		}
+	}
+}
+
+func TestBreakevenTurnScore(t *testing.T) {
+	for _, s := range [][2]int{{30, 30}, {40, 50}, {50, 40}, {50, 50}} {
+		if k := BreakevenTurnScore(s[0], s[1]); k < 17 || k > 25 {
+			t.Errorf("breakeven at %d-%d is %d, want about 20", s[0], s[1], k)
+		}
+	}
+	midgame := BreakevenTurnScore(50, 50)
+	for _, player := range []int{85, 90, 95} {
+		if k := BreakevenTurnScore(player, 50); k >= midgame || k > win-player {
+			t.Errorf("breakeven at %d-50 is %d, want it below the midgame's %d and within reach of winning",
+				player, k, midgame)
+		}
+	}
+}
+
+func TestReachableStates(t *testing.T) {
+	for _, w := range []int{2, 5, 10} {
+		states := ReachableStates(w)
+		// For each pair of banked scores, thisTurn 0 and, for a player on p,
+		// the w-p-2 turn totals from 2 to w-p-1.
+		if want := w * (w + (w-2)*(w-1)/2); len(states) != want {
+			t.Errorf("win=%d: got %d states, want %d", w, len(states), want)
+		}
+		seen := make(map[score]bool)
+		for _, s := range states {
+			if s.player+s.thisTurn >= w || s.opponent >= w || s.thisTurn == 1 || seen[s] {
+				t.Errorf("win=%d: %+v is terminal, unreachable or repeated", w, s)
+			}
+			seen[s] = true
+		}
+	}
+}
+
+func TestRollTrajectoryRisesThenFalls(t *testing.T) {
+	for _, s := range []score{{20, 20, 0}, {30, 40, 0}, {50, 50, 0}} {
+		trajectory := RollTrajectory(s, 10)
+		if len(trajectory) != 11 {
+			t.Fatalf("at %+v got %d points, want 11", s, len(trajectory))
+		}
+		peak := 0
+		for n := range trajectory {
+			if trajectory[n] > trajectory[peak] {
+				peak = n
+			}
+		}
+		for n := 1; n < len(trajectory); n++ {
+			if rising := n <= peak; rising != (trajectory[n] > trajectory[n-1]) {
+				t.Errorf("at %+v, %d rolls give %.4f after %.4f for %d, but the peak is at %d",
+					s, n, trajectory[n], trajectory[n-1], n-1, peak)
+			}
+		}
+		// A roll that doesn't bust scores 4 on average, so the best number
+		// of rolls should be about a quarter of the optimal turn total.
+		if want := float64(BreakevenTurnScore(s.player, s.opponent)) / 4; math.Abs(float64(peak)-want) > 1.5 {
+			t.Errorf("at %+v the trajectory peaks after %d rolls, want about %.1f", s, peak, want)
+		}
+	}
+}
+
+func TestGameValueFavorsTheFirstPlayer(t *testing.T) {
+	v := GameValue(win)
+	if v <= 0.5 {
+		t.Errorf("GameValue(%d) is %f, want the first player favored", win, v)
+	}
+	if v < 0.52 || v > 0.54 {
+		t.Errorf("GameValue(%d) is %f, want about 0.53", win, v)
+	}
+	if small := GameValue(10); small <= 0.5 || small >= 1 {
+		t.Errorf("GameValue(10) is %f, want between a half and 1", small)
+	}
+}
+
+// Optimal's decisions switch from rolling to staying as thisTurn rises,
+// except that near the goal it goes back to rolling, to win this turn rather
+// than give the opponent another. Against an opponent with nothing banked,
+// that only happens from 69 on, and once it rolls again it rolls to the end.
+func TestExportDecisionGridShape(t *testing.T) {
+	for _, opponent := range []int{0, 50, 99} {
+		var buf strings.Builder
+		if err := ExportDecisionGrid(&buf, opponent); err != nil {
+			t.Fatal(err)
+		}
+		rows, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != win+1 {
+			t.Fatalf("the grid against %d has %d rows, want %d", opponent, len(rows), win+1)
+		}
+		for i, row := range rows {
+			if len(row) != win+1 {
+				t.Fatalf("row %d of the grid against %d has %d cells, want %d", i, opponent, len(row), win+1)
+			}
+		}
+		for _, row := range rows[1:] {
+			player, _ := strconv.Atoi(row[0])
+			if row[1] != "R" {
+				t.Errorf("at %d against %d Optimal doesn't roll with nothing this turn", player, opponent)
+			}
+			cells := strings.Join(row[1:], "")
+			if len(cells) != win-player || strings.Trim(cells, "RS") != "" {
+				t.Errorf("at %d against %d the cells are %q, want R or S short of winning and empty after", player, opponent, row[1:])
+			}
+			if opponent != 0 {
+				continue
+			}
+			rollsAgain := strings.Contains(cells, "SR")
+			if player < 69 && rollsAgain {
+				t.Errorf("at %d against 0 Optimal rolls again after staying: %s", player, cells)
+			}
+			if rollsAgain && strings.Contains(cells[strings.Index(cells, "SR"):], "RS") {
+				t.Errorf("at %d against 0 Optimal stays again after rolling again: %s", player, cells)
+			}
+		}
+	}
+	if err := ExportDecisionGrid(io.Discard, win); err == nil {
+		t.Error("a grid against an opponent who has won was written")
+	}
+}
+
+func TestExplainDecisionMatchesOptimal(t *testing.T) {
+	optimal := &Optimal{}
+	for _, s := range []score{{0, 0, 0}, {0, 0, 30}, {50, 80, 15}, {90, 20, 5}, {95, 95, 3}} {
+		explanation := optimal.ExplainDecision(s)
+		var chosen, first, second string
+		var pChosen, pOther float64
+		if _, err := fmt.Sscanf(explanation, "%s win prob if %s %f vs if %s %f", &chosen, &first, &pChosen, &second, &pOther); err != nil {
+			t.Fatalf("explaining %+v: can't parse %q: %v", s, explanation, err)
+		}
+		want := "Stay:"
+		if isRoll(optimal.nextAction(s)) {
+			want = "Roll:"
+		}
+		if chosen != want || first != strings.ToLower(strings.TrimSuffix(want, ":")) || second == first {
+			t.Errorf("at %+v the explanation %q doesn't lead with %q", s, explanation, want)
+		}
+		if math.Abs(pChosen-WinProbability(s)) > 0.005 || pOther > pChosen {
+			t.Errorf("at %+v the explanation %q disagrees with the win probability %.4f", s, explanation, WinProbability(s))
+		}
+	}
+}
+
+func TestPeekOptimalNeverBusts(t *testing.T) {
+	cfg := GameConfig{Peek: true}
+	for game := int64(0); game < 200; game++ {
+		_, err := cfg.PlayGame(&PeekOptimal{}, &Optimal{}, int(game%2), cfg.RandomDie(game), func(turn Turn) {
+			if turn.Player == 0 && turn.Die == 1 {
+				t.Fatalf("PeekOptimal rolled a 1 in game %d", game)
+			}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, err := cfg.CompareStrategies(&PeekOptimal{}, &Optimal{}, 2000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.winRateA() < 0.8 {
+		t.Errorf("PeekOptimal won %.3f of its games against Optimal when it could peek, want nearly all", c.winRateA())
+	}
+}
+
+func TestAnnealingStrategyInTheEndgame(t *testing.T) {
+	annealing, optimal := NewAnnealingStrategy(0.05, 0.9, 20, 1), &Optimal{}
+	if !isRoll(annealing.nextAction(score{85, 98, 10})) {
+		t.Error("AnnealingStrategy stays at 95 to 98 with its opponent about to win")
+	}
+	agree, n, loss := 0, 0, 0.0
+	for i, s := range ReachableStates(win) {
+		if i%50 != 0 || (s.player+s.thisTurn < win-annealingRange && s.opponent < win-annealingRange) {
+			continue
+		}
+		n++
+		a := annealing.nextAction(s)
+		if isRoll(a) == isRoll(optimal.nextAction(s)) {
+			agree++
+		}
+		loss += DecisionLoss(s, a)
+	}
+	if rate := float64(agree) / float64(n); rate < 0.9 || loss/float64(n) > 0.01 {
+		t.Errorf("in the endgame AnnealingStrategy agreed with Optimal at %.3f of states and lost %.4f on average, want at least 0.9 and at most 0.01",
+			rate, loss/float64(n))
+	}
+
+	// Before the endgame it plays like StayAtK{20}, without searching.
+	searched, fresh := NewAnnealingStrategy(0.05, 0.9, 20, 1), NewAnnealingStrategy(0.05, 0.9, 20, 1)
+	stayAt20 := &StayAtK{20}
+	for _, s := range ReachableStates(win - annealingRange) {
+		if isRoll(searched.nextAction(s)) != isRoll(stayAt20.nextAction(s)) {
+			t.Fatalf("at %+v, before the endgame, AnnealingStrategy doesn't play like StayAtK{20}", s)
+		}
+	}
+	if searched.rng.Int63() != fresh.rng.Int63() {
+		t.Error("AnnealingStrategy drew random numbers before the endgame")
+	}
+}
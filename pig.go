@@ -7,8 +7,12 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"math/rand"
+	"net/http"
+	"os"
+	"time"
 )
 
 const (
@@ -22,26 +26,74 @@ type score struct {
 	player, opponent, thisTurn int
 }
 
-// An action transitions stochastically to a resulting score.
-type action func(current score) (result score, turnIsOver bool)
+// Key packs s into a single number, ten bits for each of player, opponent
+// and thisTurn, for use as a compact map key. Each must be below 1024.
+func (s score) Key() uint32 {
+	return uint32(s.player)<<20 | uint32(s.opponent)<<10 | uint32(s.thisTurn)
+}
+
+// A die returns the value of a single roll.
+type die func() int
+
+// sixSided is a fair six-sided die.
+func sixSided() int {
+	return rand.Intn(6) + 1 // A random int in [1, 6]
+}
+
+// An action transitions stochastically to a resulting score, rolling d if
+// it needs a die.
+type action func(current score, d die) (result score, turnIsOver bool)
 
 // roll returns the (result, turnIsOver) outcome of simulating a die roll. 
 // If the roll value is 1, then thisTurn score is abandoned, and the players'
 // roles swap.  Otherwise, the roll value is added to thisTurn.
-func roll(s score) (score, bool) {
-	outcome := rand.Intn(6) + 1 // A random int in [1, 6]
+func roll(s score, d die) (score, bool) {
+	outcome := d()
 	if outcome == 1 {
 		return score{s.opponent, s.player, 0}, true
 	}
 	return score{s.player, s.opponent, outcome + s.thisTurn}, false
 }
 
+// rollK returns the (result, turnIsOver) outcome of rolling k dice at once.
+// If any of them is 1, then thisTurn score is abandoned, and the players'
+// roles swap.  Otherwise, the values of all k dice are added to thisTurn.
+func rollK(s score, k int, d die) (score, bool) {
+	total, bust := 0, false
+	for i := 0; i < k; i++ {
+		outcome := d()
+		if outcome == 1 {
+			bust = true
+		}
+		total += outcome
+	}
+	if bust {
+		return score{s.opponent, s.player, 0}, true
+	}
+	return score{s.player, s.opponent, total + s.thisTurn}, false
+}
+
+// rollDice returns the action of rolling k dice at once.
+func rollDice(k int) action {
+	return func(s score, d die) (score, bool) {
+		return rollK(s, k, d)
+	}
+}
+
 // stay returns the (result, turnIsOver) outcome of staying.
 // thisTurn score is added to the player's score, and the players' roles swap.
-func stay(s score) (score, bool) {
+func stay(s score, d die) (score, bool) {
 	return score{s.opponent, s.player + s.thisTurn, 0}, true
 }
 
+// bankHalf returns the (result, turnIsOver) outcome of banking half of
+// thisTurn, rounded down, and keeping the rest at risk. The turn goes on.
+// Only games with the BankHalf variant allow it.
+func bankHalf(s score, d die) (score, bool) {
+	half := s.thisTurn / 2
+	return score{s.player + half, s.opponent, s.thisTurn - half}, false
+}
+
 // A strategy chooses an action for any given score.
 type Strategy interface {
 	fmt.Stringer
@@ -64,33 +116,341 @@ func (self *StayAtK) String() string {
 	return fmt.Sprintf("Stay at %d", self.k)
 }
 
-type Random struct {}
+// StayAtKDice rolls dice dice at once until thisTurn is at least k, then
+// stays.
+type StayAtKDice struct {
+	k, dice int
+}
+
+func (self *StayAtKDice) nextAction(s score) action {
+	if s.thisTurn >= self.k {
+		return stay
+	}
+	return rollDice(self.dice)
+}
+
+func (self *StayAtKDice) String() string {
+	return fmt.Sprintf("Stay at %d rolling %d", self.k, self.dice)
+}
+
+// ExactTarget plays for landing exactly on target. It rolls until thisTurn
+// is at least k, but banks as soon as it is within one die of target, and
+// from then on rolls every turn until it lands on target or busts. Banking
+// any closer would only lower its chances, since fewer sequences of rolls
+// add up to a smaller total. Banking target-1 would leave it needing a 1,
+// which always busts, so from there it rolls to throw the turn away
+// instead.
+type ExactTarget struct {
+	k, target int
+}
+
+func (self *ExactTarget) nextAction(s score) action {
+	need := self.target - s.player - s.thisTurn
+	if need == 1 || s.thisTurn == 0 || self.target-s.player <= 6 {
+		return roll
+	}
+	if need <= 6 || s.thisTurn >= self.k {
+		return stay
+	}
+	return roll
+}
+
+func (self *ExactTarget) String() string {
+	return fmt.Sprintf("Exact target %d (stay at %d)", self.target, self.k)
+}
+
+// A Cloneable strategy can make copies of itself, so that each concurrent
+// series can play with its own copy instead of sharing one.
+type Cloneable interface {
+	Clone() Strategy
+}
+
+// A Seeder is a strategy that draws random numbers from a source that can be
+// reseeded.
+type Seeder interface {
+	Seed(seed int64)
+}
+
+// clone returns a copy of strategy if it is Cloneable, or strategy itself.
+func clone(strategy Strategy) Strategy {
+	if c, ok := strategy.(Cloneable); ok {
+		return c.Clone()
+	}
+	return strategy
+}
+
+// cloneAll returns a lineup of clones of each of strategies.
+func cloneAll(strategies []Strategy) []Strategy {
+	clones := make([]Strategy, len(strategies))
+	for i, strategy := range strategies {
+		clones[i] = clone(strategy)
+	}
+	return clones
+}
+
+// BankHalfAtK rolls until thisTurn is at least k, then banks half of it and
+// keeps rolling, never staying. It needs the BankHalf variant.
+type BankHalfAtK struct {
+	k int
+}
+
+func (self *BankHalfAtK) nextAction(s score) action {
+	if s.thisTurn >= self.k {
+		return bankHalf
+	}
+	return roll
+}
+
+func (self *BankHalfAtK) String() string {
+	return fmt.Sprintf("Bank half at %d", self.k)
+}
+
+// Random rolls or stays with equal probability. It draws from rng, or from
+// the global source if rng is nil, and is not safe for concurrent use unless
+// rng is nil.
+type Random struct {
+	rng *rand.Rand
+}
+
+// NewRandom returns a Random that draws from its own source seeded with seed.
+func NewRandom(seed int64) *Random {
+	return &Random{rand.New(rand.NewSource(seed))}
+}
 
 func (self *Random) nextAction(s score) action {
-	if rand.Float64() > 0.5 {
+	var f float64
+	if self.rng != nil {
+		f = self.rng.Float64()
+	} else {
+		f = rand.Float64()
+	}
+	if f > 0.5 {
 		return stay
 	}
 	return roll
 }
 
+// Seed gives self its own source seeded with seed.
+func (self *Random) Seed(seed int64) {
+	self.rng = rand.New(rand.NewSource(seed))
+}
+
+// Clone returns a Random with its own source, seeded from self's.
+func (self *Random) Clone() Strategy {
+	if self.rng != nil {
+		return NewRandom(self.rng.Int63())
+	}
+	return NewRandom(rand.Int63())
+}
+
 func (self *Random) String() string {
 	return "Random!"
 }
 
+// A Resigner strategy may resign a game: it is asked before each of its
+// decisions, and if it resigns, its opponent wins at once. Strategies that
+// are not Resigners never resign.
+type Resigner interface {
+	ShouldResign(s score) bool
+}
+
+// A StartAware strategy is told at the start of each game whether it moves
+// first.
+type StartAware interface {
+	SetStartingPlayer(first bool)
+}
+
+// FirstMoverAware is like StayAtK, but stays at firstK in games where it
+// moves first and at secondK in games where it moves second. It keeps the
+// starting role of its current game, so an instance must not be shared
+// between games played at the same time; concurrent series use clones.
+type FirstMoverAware struct {
+	firstK, secondK int
+	first           bool
+}
+
+func (self *FirstMoverAware) SetStartingPlayer(first bool) {
+	self.first = first
+}
+
+func (self *FirstMoverAware) Clone() Strategy {
+	c := *self
+	return &c
+}
+
+func (self *FirstMoverAware) nextAction(s score) action {
+	k := self.secondK
+	if self.first {
+		k = self.firstK
+	}
+	if s.thisTurn >= k {
+		return stay
+	}
+	return roll
+}
+
+func (self *FirstMoverAware) String() string {
+	return fmt.Sprintf("First-mover aware %d/%d", self.firstK, self.secondK)
+}
+
+// A Resettable strategy keeps state between games, and is reset before each
+// series so that nothing it learned carries over to a new opponent.
+type Resettable interface {
+	Reset()
+}
+
+// reset resets each of strategies that is Resettable.
+func reset(strategies ...Strategy) {
+	for _, strategy := range strategies {
+		if r, ok := strategy.(Resettable); ok {
+			r.Reset()
+		}
+	}
+}
+
 // play simulates a Pig game and returns the winner (0 or 1).
 func play(strategy0, strategy1 Strategy) int {
+	// Randomly decide who plays first
+	return playGame(GameConfig{}, strategy0, strategy1, rand.Intn(2), sixSided, nil)
+}
+
+// playGame simulates a Pig game under cfg in which player first moves first
+// and every roll is made with dice. It returns the winner (0, 1 or Draw),
+// passing every action taken to record unless it is nil.
+func playGame(cfg GameConfig, strategy0, strategy1 Strategy, first int, dice die, record func(Turn)) int {
+	return playDetailed(cfg, strategy0, strategy1, first, dice, record).Winner
+}
+
+// Draw is the winner of a game that ended without one, which only variants
+// allow.
+const Draw = -1
+
+// A GameDetail summarizes how a game ended.
+type GameDetail struct {
+	Winner int    // The winning player (0 or 1), or Draw
+	Scores [2]int // Each player's final score, including the winner's last turn
+	Turns  int    // The number of turns started by either player
+	// Forfeited is whether the loser forfeited, by resigning or by missing
+	// the DecisionDeadline.
+	Forfeited bool
+}
+
+// Margin returns how many points the winner won by, or 0 for a draw.
+func (self GameDetail) Margin() int {
+	if self.Winner == Draw {
+		return 0
+	}
+	return self.Scores[self.Winner] - self.Scores[1-self.Winner]
+}
+
+// playDetailed is like playGame, but returns the details of the game.
+func playDetailed(cfg GameConfig, strategy0, strategy1 Strategy, first int, dice die, record func(Turn)) GameDetail {
+	return playFrom(cfg, strategy0, strategy1, first, score{}, dice, record)
+}
+
+// playFrom is like playDetailed, but starts the game at initial, from the
+// point of view of player first, who is to move.
+func playFrom(cfg GameConfig, strategy0, strategy1 Strategy, first int, initial score, dice die, record func(Turn)) GameDetail {
 	strategies := []Strategy{strategy0, strategy1}
-	var s score
+	s := initial
 	var turnIsOver bool
-	currentPlayer := rand.Intn(2) // Randomly decide who plays first
-	for s.player+s.thisTurn < win {
-		action := strategies[currentPlayer].nextAction(s)
-		s, turnIsOver = action(s)
+	var outcome, peeked int
+	firstRoll := true
+	rolls := 0 // This turn
+	// next returns what the next roll will score, after any reroll and
+	// multiplier, as peekers see it.
+	next := func() int {
+		value := dice()
+		if cfg.SafeFirstRoll && firstRoll && value == 1 {
+			value = dice()
+		}
+		if m, ok := cfg.FaceMultipliers[value]; ok {
+			value *= m
+		}
+		return value
+	}
+	d := func() int {
+		value := peeked
+		if value != 0 {
+			peeked = 0
+		} else {
+			value = next()
+		}
+		firstRoll = false
+		rolls++
+		outcome += value
+		return value
+	}
+	currentPlayer := first
+	turns := [2]int{}
+	turns[currentPlayer] = 1
+	for i, strategy := range strategies {
+		if aware, ok := strategy.(StartAware); ok {
+			aware.SetStartingPlayer(i == currentPlayer)
+		}
+	}
+	// forfeit ends the game with currentPlayer forfeiting it.
+	forfeit := func() GameDetail {
+		detail := GameDetail{Winner: 1 - currentPlayer, Turns: turns[0] + turns[1], Forfeited: true}
+		detail.Scores[currentPlayer] = s.player
+		detail.Scores[1-currentPlayer] = s.opponent
+		return detail
+	}
+	for s.player+s.thisTurn < cfg.winningScore() {
+		if r, ok := strategies[currentPlayer].(Resigner); ok && r.ShouldResign(s) {
+			return forfeit()
+		}
+		outcome, peeked = 0, 0
+		if cfg.Peek {
+			peeked = next()
+		}
+		g := GameState{s, turns[currentPlayer]}
+		var start time.Time
+		if cfg.DecisionDeadline > 0 {
+			start = time.Now()
+		}
+		action := decide(strategies[currentPlayer], g, peeked)
+		if cfg.DecisionDeadline > 0 && time.Since(start) > cfg.DecisionDeadline {
+			return forfeit()
+		}
+		before := s
+		s, turnIsOver = action(s, d)
+		if outcome == 0 && !turnIsOver && (!cfg.BankHalf || s == before) {
+			// Neither rolled nor ended the turn: only the BankHalf variant
+			// allows that, and then only if it makes progress.
+			s, turnIsOver = stay(before, d)
+		}
+		if cfg.MustHitExact && !turnIsOver && s.player+s.thisTurn > cfg.winningScore() {
+			s, turnIsOver = score{s.opponent, s.player, 0}, true
+		}
+		if cfg.MaxRollsPerTurn > 0 && !turnIsOver && rolls >= cfg.MaxRollsPerTurn &&
+			s.player+s.thisTurn < cfg.winningScore() {
+			// Out of rolls: record the roll, then stay for the player.
+			if record != nil {
+				record(newTurn(currentPlayer, outcome, before, s, false))
+			}
+			before, outcome = s, 0
+			s, turnIsOver = stay(s, d)
+		}
+		if record != nil {
+			record(newTurn(currentPlayer, outcome, before, s, turnIsOver))
+		}
 		if turnIsOver {
 			currentPlayer = (currentPlayer + 1) % 2
+			firstRoll, rolls = true, 0
+			if turns[0]+turns[1] == cfg.MaxTurns {
+				detail := GameDetail{Winner: Draw, Turns: cfg.MaxTurns}
+				detail.Scores[currentPlayer] = s.player
+				detail.Scores[1-currentPlayer] = s.opponent
+				return detail
+			}
+			turns[currentPlayer]++
 		}
 	}
-	return currentPlayer
+	detail := GameDetail{Winner: currentPlayer, Turns: turns[0] + turns[1]}
+	detail.Scores[currentPlayer] = s.player + s.thisTurn
+	detail.Scores[1-currentPlayer] = s.opponent
+	return detail
 }
 
 // roundRobin simulates a series of games between every pair of strategies.
@@ -98,9 +458,10 @@ func roundRobin(strategies []Strategy) ([]int, int) {
 	wins := make([]int, len(strategies))
 	results := make(chan []int)
 	for i := 0; i < len(strategies); i++ {
-		go func(i int) {
+		go func(i int, strategies []Strategy) {
 			winCount := make([]int, len(strategies))
 			for j := i + 1; j < len(strategies); j++ {
+				reset(strategies[i], strategies[j])
 				for k := 0; k < gamesPerSeries; k++ {
 					winner := play(strategies[i], strategies[j])
 					if winner == 0 {
@@ -111,7 +472,7 @@ func roundRobin(strategies []Strategy) ([]int, int) {
 				}
 			}
 			results <- winCount
-		}(i)
+		}(i, cloneAll(strategies))
 	}
 	for i := 0; i < len(strategies); i++ {
 		r := <-results
@@ -127,6 +488,12 @@ func roundRobin(strategies []Strategy) ([]int, int) {
 // each value and its percentage of the sum of all values.
 // e.g., ratios(1, 2, 3) = "1/6 (16.7%), 2/6 (33.3%), 3/6 (50.0%)"
 func ratioString(vals ...int) string {
+	return ratioStringPrecision(1, vals...)
+}
+
+// ratioStringPrecision is like ratioString, but shows each percentage with
+// precision decimal places.
+func ratioStringPrecision(precision int, vals ...int) string {
 	total := 0
 	for _, val := range vals {
 		total += val
@@ -137,22 +504,112 @@ func ratioString(vals ...int) string {
 			s += ", "
 		}
 		pct := 100 * float64(val) / float64(total)
-		s += fmt.Sprintf("%d/%d (%0.1f%%)", val, total, pct)
+		s += fmt.Sprintf("%d/%d (%s%%)", val, total, formatPercent(pct, precision))
 	}
 	return s
 }
 
 func main() {
-	strategies := make([]Strategy, win + 1)
-	var k int
-	for k = 0; k < win; k++ {
-		strategies[k] = &StayAtK{k + 1}
+	format := flag.String("format", "text", "output format: text, md or prom")
+	seed := flag.Int64("seed", 0, "random seed, or 0 to pick one from the clock")
+	games := flag.Int("games", gamesPerSeries, "games per series")
+	var plugins []Strategy
+	flag.Func("plugin", "load a strategy plugin (repeatable; Linux, FreeBSD and macOS only)", func(path string) error {
+		s, err := LoadPlugin(path)
+		if err == nil {
+			plugins = append(plugins, s)
+		}
+		return err
+	})
+	precision := flag.Int("precision", 1, "decimal places in percentages")
+	sweep := flag.String("sweep", "", "sweep a parameter against an opponent, e.g. \"stayat:k=1..30 vs random\"")
+	serveAgainst := flag.String("serve", "", "play a game over stdin and stdout against a strategy, e.g. \"stayat:20\"")
+	autotune := flag.String("autotune", "", "tune a strategy, e.g. \"stayat\", against the lineup and report its rank")
+	httpAddr := flag.String("http", "", "serve the simulator over HTTP at this address, e.g. \":8080\"")
+	experiment := flag.String("experiment", "", "run a tournament instead, e.g. \"roundrobin(games=1000){stayat:20, random, optimal}\"")
+	flag.Parse()
+	newReporter, ok := reporters[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	if *precision < 0 {
+		fmt.Fprintf(os.Stderr, "-precision must not be negative, not %d\n", *precision)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *httpAddr != "" {
+		fmt.Fprintln(os.Stderr, http.ListenAndServe(*httpAddr, Handler()))
+		os.Exit(1)
+	}
+
+	*seed = resolveSeed(*seed)
+	if *sweep != "" {
+		fmt.Fprintf(os.Stderr, "Seed %d\n", *seed)
+		if err := runSweep(os.Stdout, *sweep, *games, *seed); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if *serveAgainst != "" {
+		fmt.Fprintf(os.Stderr, "Seed %d\n", *seed)
+		opponent, err := ParseStrategyExpr(*serveAgainst)
+		if err == nil {
+			err = serve(os.Stdin, os.Stdout, opponent, *seed)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	if *experiment != "" {
+		result, err := RunExperiment(*experiment)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "Seed %d\n", result.Seed)
+		reporter := newReporter(ReportOptions{Precision: *precision})
+		if err := reporter.Report(os.Stdout, result.Standings); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	strategies := make([]Strategy, 0, win+3)
+	for k := 0; k < win; k++ {
+		strategies = append(strategies, &StayAtK{k + 1})
+	}
+	strategies = append(strategies, NewRandom(*seed), &Optimal{}, &ProbThreshold{0.01})
+	strategies = append(strategies, plugins...)
+	if *autotune != "" {
+		fmt.Fprintf(os.Stderr, "Seed %d\n", *seed)
+		proto, err := LookupStrategy(*autotune)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		t, ok := proto.(Tunable)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%v has no parameters to tune\n", proto)
+			os.Exit(2)
+		}
+		tuned, rank := AutoTuneAndCompete(t, strategies, *games, *seed)
+		fmt.Printf("%v ranks %d of %d\n", tuned, rank, len(strategies)+1)
+		return
 	}
-	strategies[k] = &Random{}
-	wins, games := roundRobin(strategies)
+	result := Simulate(strategies, *games, *seed)
 
-	for i := range strategies {
-		fmt.Printf("Wins, losses %v: %s\n",
-			strategies[i], ratioString(wins[i], games-wins[i]))
+	fmt.Fprintf(os.Stderr, "Seed %d\n", result.Seed)
+	reporter := newReporter(ReportOptions{Precision: *precision})
+	if err := reporter.Report(os.Stdout, result.Standings); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 }
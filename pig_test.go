@@ -0,0 +1,177 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestFirstMoverAwareThresholdByRole(t *testing.T) {
+	for _, test := range []struct {
+		first bool
+		k     int
+	}{{true, 15}, {false, 25}} {
+		f := &FirstMoverAware{firstK: 15, secondK: 25}
+		f.SetStartingPlayer(test.first)
+		if !isRoll(f.nextAction(score{0, 0, test.k - 1})) {
+			t.Errorf("first=%v: stayed at %d, want to roll below %d", test.first, test.k-1, test.k)
+		}
+		if isRoll(f.nextAction(score{0, 0, test.k})) {
+			t.Errorf("first=%v: rolled at %d, want to stay", test.first, test.k)
+		}
+	}
+}
+
+func TestPlayTellsStartAwareStrategiesTheirRole(t *testing.T) {
+	a := &FirstMoverAware{firstK: 15, secondK: 25}
+	b := &FirstMoverAware{firstK: 15, secondK: 25}
+	playGame(GameConfig{}, a, b, 1, sixSided, nil)
+	if a.first || !b.first {
+		t.Errorf("player 1 moved first, but a.first=%v and b.first=%v", a.first, b.first)
+	}
+}
+
+// learner is a stateful strategy whose threshold rises by one every game,
+// until it is reset. Its clones share starts, which records its threshold
+// at the start of every game.
+type learner struct {
+	k      int
+	starts *syncInts
+}
+
+// syncInts is a list of ints safe for concurrent appends.
+type syncInts struct {
+	mu sync.Mutex
+	xs []int
+}
+
+func (self *syncInts) add(x int) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.xs = append(self.xs, x)
+}
+
+func (self *learner) SetStartingPlayer(first bool) {
+	self.starts.add(self.k)
+	self.k++
+}
+
+func (self *learner) Reset() { self.k = 20 }
+
+func (self *learner) Clone() Strategy { return &learner{self.k, self.starts} }
+
+func (self *learner) nextAction(s score) action { return (&StayAtK{self.k}).nextAction(s) }
+
+func (self *learner) String() string { return "learner" }
+
+func TestSeriesStartWithResetStrategies(t *testing.T) {
+	starts := &syncInts{}
+	const games = 5
+	Simulate([]Strategy{&learner{35, starts}, &StayAtK{20}, &StayAtK{25}}, games, 1)
+	fresh := 0
+	for _, k := range starts.xs {
+		if k < 20 || k >= 20+games {
+			t.Fatalf("a game started with threshold %d, want 20 to %d", k, 20+games-1)
+		}
+		if k == 20 {
+			fresh++
+		}
+	}
+	if len(starts.xs) != 2*games || fresh != 2 {
+		t.Errorf("got %d games, %d with a fresh threshold; want %d and 2", len(starts.xs), fresh, 2*games)
+	}
+}
+
+// scripted returns a die that rolls rolls in order.
+func scripted(rolls ...int) die {
+	return func() int {
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+}
+
+func TestRollK(t *testing.T) {
+	for _, test := range []struct {
+		rolls      []int
+		want       score
+		turnIsOver bool
+	}{
+		{[]int{2, 3, 6}, score{10, 20, 16}, false},
+		{[]int{2, 1, 6}, score{20, 10, 0}, true},
+		{[]int{1, 1, 1}, score{20, 10, 0}, true},
+		{[]int{5, 5, 1}, score{20, 10, 0}, true},
+	} {
+		got, turnIsOver := rollDice(3)(score{10, 20, 5}, scripted(test.rolls...))
+		if got != test.want || turnIsOver != test.turnIsOver {
+			t.Errorf("rolling %v: got %+v, %v; want %+v, %v", test.rolls, got, turnIsOver, test.want, test.turnIsOver)
+		}
+	}
+}
+
+func TestClonedRandomPlaysConcurrently(t *testing.T) {
+	shared := NewRandom(1)
+	pairs := make([][2]Strategy, 16)
+	for i := range pairs {
+		pairs[i] = [2]Strategy{shared, &StayAtK{20}}
+	}
+	for _, c := range BatchCompare(pairs, 200, 1) {
+		if c.WinsA+c.WinsB+c.Draws != 200 {
+			t.Errorf("got %+v, want 200 games", c)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		a, b := clone(shared), clone(shared)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			CompareStrategies(a, b, 200, int64(i))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestBankHalf(t *testing.T) {
+	s, turnIsOver := bankHalf(score{10, 20, 15}, nil)
+	if want := (score{17, 20, 8}); s != want || turnIsOver {
+		t.Errorf("banking half of 15 gave %+v, %v, want %+v, false", s, turnIsOver, want)
+	}
+
+	cfg := GameConfig{BankHalf: true, MaxTurns: 1}
+	var turns []Turn
+	d := playFrom(cfg, &BankHalfAtK{10}, &StayAtK{20}, 0, score{0, 0, 12}, scripted(3, 1), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	want := []Turn{{0, 0, 6, false}, {0, 3, 9, false}, {0, 1, 0, true}}
+	if !slices.Equal(turns, want) {
+		t.Errorf("banking half of 12 then rolling 3 and 1 recorded %+v, want %+v", turns, want)
+	}
+	if d.Scores != [2]int{6, 0} {
+		t.Errorf("the half banked left scores %v, want [6 0]", d.Scores)
+	}
+}
+
+// quitter plays like StayAtK{20}, but resigns once its opponent has banked
+// 20 more than it.
+type quitter struct {
+	StayAtK
+}
+
+func (self *quitter) ShouldResign(s score) bool {
+	return s.opponent-s.player >= 20
+}
+
+func TestResigningLosesAtOnce(t *testing.T) {
+	var turns []Turn
+	detail := playFrom(GameConfig{}, &StayAtK{20}, &quitter{StayAtK{20}}, 0, score{}, scripted(6, 6, 6, 6), func(turn Turn) {
+		turns = append(turns, turn)
+	})
+	if want := (GameDetail{Winner: 0, Scores: [2]int{24, 0}, Turns: 2, Forfeited: true}); detail != want {
+		t.Errorf("resigning 24 to 0 ended the game %+v, want %+v", detail, want)
+	}
+	if n := len(turns); n != 5 || turns[n-1].Player != 0 {
+		t.Errorf("the game recorded %+v, want player 0's turn and nothing of player 1's", turns)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// A PluginStrategy is what a strategy plugin provides. Plugins cannot
+// implement Strategy itself, whose methods are unexported, so instead they
+// export a function
+//
+//	func NewStrategy() interface {
+//		String() string
+//		Roll(player, opponent, thisTurn int) bool
+//	}
+//
+// whose result reports whether to roll given the score. Go plugins are
+// only supported on Linux, FreeBSD and macOS, and must be built with the
+// same Go version as the binary that loads them.
+type PluginStrategy = interface {
+	String() string
+	Roll(player, opponent, thisTurn int) bool
+}
+
+// pluginStrategy adapts a PluginStrategy to a Strategy.
+type pluginStrategy struct {
+	PluginStrategy
+}
+
+func (self pluginStrategy) nextAction(s score) action {
+	if self.Roll(s.player, s.opponent, s.thisTurn) {
+		return roll
+	}
+	return stay
+}
+
+// LoadPlugin opens the plugin at path and returns the strategy made by its
+// NewStrategy function.
+func LoadPlugin(path string) (Strategy, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading strategy plugin: %v", err)
+	}
+	sym, err := p.Lookup("NewStrategy")
+	if err != nil {
+		return nil, fmt.Errorf("strategy plugin %s does not export NewStrategy", path)
+	}
+	newStrategy, ok := sym.(func() PluginStrategy)
+	if !ok {
+		return nil, fmt.Errorf("strategy plugin %s: NewStrategy is a %T, not a func() PluginStrategy", path, sym)
+	}
+	return pluginStrategy{newStrategy()}, nil
+}
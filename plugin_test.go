@@ -0,0 +1,61 @@
+//go:build (linux || darwin || freebsd) && cgo && !race
+
+// Go plugins need cgo, and must be built with the same flags as the binary
+// that loads them, which a plugin built here without -race isn't.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// A plugin that stays at 20, like StayAtK{20}.
+const stayAt20Plugin = `package main
+
+type stayAt20 struct{}
+
+func (stayAt20) String() string { return "Plugin stay at 20" }
+
+func (stayAt20) Roll(player, opponent, thisTurn int) bool { return thisTurn < 20 }
+
+func NewStrategy() interface {
+	String() string
+	Roll(player, opponent, thisTurn int) bool
+} {
+	return stayAt20{}
+}
+`
+
+func TestLoadPluginCompetes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	dir := t.TempDir()
+	src, so := filepath.Join(dir, "stayat20.go"), filepath.Join(dir, "stayat20.so")
+	if err := os.WriteFile(src, []byte(stayAt20Plugin), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	build := exec.Command(filepath.Join(runtime.GOROOT(), "bin", "go"), "build", "-buildmode=plugin", "-o", so, src)
+	build.Dir = dir
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("building the plugin: %v\n%s", err, out)
+	}
+	s, err := LoadPlugin(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "Plugin stay at 20" {
+		t.Errorf("the plugin's strategy is called %q", s)
+	}
+	got := CompareStrategies(s, &StayAtK{25}, 500, 1)
+	if want := CompareStrategies(&StayAtK{20}, &StayAtK{25}, 500, 1); got != want {
+		t.Errorf("the plugin did %+v against StayAtK{25}, want %+v like StayAtK{20}", got, want)
+	}
+	if _, err := LoadPlugin(filepath.Join(dir, "missing.so")); err == nil {
+		t.Error("loading a missing plugin succeeded")
+	}
+}
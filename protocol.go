@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// protocolStrategy is a strategy played by an external program over a line
+// protocol; see ProtocolStrategy.
+type protocolStrategy struct {
+	in  *bufio.Scanner
+	out io.Writer
+	err error // Once reading or writing fails, the strategy always stays
+}
+
+// ProtocolStrategy returns a strategy that asks a program at the other end of
+// in and out for every decision. For each decision it writes the score as a
+// line of three numbers, the player's banked score, the opponent's and the
+// turn total:
+//
+//	45 60 12
+//
+// and reads back a line saying "roll" or "stay". Anything else is taken to
+// mean stay. If out can't be written or in ends, the strategy stays for the
+// rest of its games, which forfeits them in time.
+func ProtocolStrategy(in io.Reader, out io.Writer) Strategy {
+	return &protocolStrategy{in: bufio.NewScanner(in), out: out}
+}
+
+func (self *protocolStrategy) nextAction(s score) action {
+	if self.err != nil {
+		return stay
+	}
+	if _, err := fmt.Fprintf(self.out, "%d %d %d\n", s.player, s.opponent, s.thisTurn); err != nil {
+		self.err = err
+		return stay
+	}
+	if !self.in.Scan() {
+		self.err = self.in.Err()
+		if self.err == nil {
+			self.err = io.EOF
+		}
+		return stay
+	}
+	if strings.TrimSpace(self.in.Text()) == "roll" {
+		return roll
+	}
+	return stay
+}
+
+func (self *protocolStrategy) String() string {
+	return "Protocol"
+}
+
+// serve plays a game between a program speaking the ProtocolStrategy
+// protocol over in and out, which moves first, and opponent, with dice
+// seeded with seed. When the game is over it writes a last line saying
+// "won" or "lost" and the final scores, the program's first:
+//
+//	lost 64 100
+func serve(in io.Reader, out io.Writer, opponent Strategy, seed int64) error {
+	detail := PlayDetailed(ProtocolStrategy(in, out), opponent, 0, seed)
+	result := "lost"
+	if detail.Winner == 0 {
+		result = "won"
+	}
+	_, err := fmt.Fprintf(out, "%s %d %d\n", result, detail.Scores[0], detail.Scores[1])
+	return err
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestServePlaysAnExternalBot(t *testing.T) {
+	toBot, fromServe := io.Pipe()
+	toServe, fromBot := io.Pipe()
+	last := make(chan string, 1)
+	// The bot stays at 20, like StayAtK{20}.
+	go func() {
+		defer fromBot.Close()
+		lines := bufio.NewScanner(toBot)
+		for lines.Scan() {
+			var player, opponent, thisTurn int
+			if _, err := fmt.Sscanf(lines.Text(), "%d %d %d", &player, &opponent, &thisTurn); err != nil {
+				last <- lines.Text()
+				return
+			}
+			reply := "roll"
+			if thisTurn >= 20 {
+				reply = "stay"
+			}
+			fmt.Fprintln(fromBot, reply)
+		}
+		last <- ""
+	}()
+	if err := serve(toServe, fromServe, &StayAtK{25}, 9); err != nil {
+		t.Fatal(err)
+	}
+	fromServe.Close()
+	d := PlayDetailed(&StayAtK{20}, &StayAtK{25}, 0, 9)
+	result := "lost"
+	if d.Winner == 0 {
+		result = "won"
+	}
+	if got, want := <-last, fmt.Sprintf("%s %d %d", result, d.Scores[0], d.Scores[1]); got != want {
+		t.Errorf("the bot was told %q, want %q", got, want)
+	}
+}
+
+func TestProtocolStrategyStaysOnceTheBotIsGone(t *testing.T) {
+	var out strings.Builder
+	s := ProtocolStrategy(strings.NewReader("roll\nbogus\n"), &out)
+	for i, want := range []bool{true, false, false, false} {
+		if isRoll(s.nextAction(score{0, 0, 2 * i})) != want {
+			t.Errorf("decision %d: rolled=%v, want %v", i, !want, want)
+		}
+	}
+	if want := "0 0 0\n0 0 2\n0 0 4\n"; out.String() != want {
+		t.Errorf("wrote %q, want %q, stopping once the input ended", out.String(), want)
+	}
+}
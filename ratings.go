@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// WriteRatings writes ratings, keyed by strategy name, to w as CSV: a
+// header and then one name,rating row per strategy, from the highest rating
+// down, in a form LoadRatings reads back exactly.
+func WriteRatings(w io.Writer, ratings map[string]float64) error {
+	names := make([]string, 0, len(ratings))
+	for name := range ratings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ratings[names[i]] != ratings[names[j]] {
+			return ratings[names[i]] > ratings[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "rating"})
+	for _, name := range names {
+		cw.Write([]string{name, strconv.FormatFloat(ratings[name], 'g', -1, 64)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// LoadRatings reads strategy ratings from r as CSV rows of the form
+//
+//	name,rating
+//
+// optionally after a header row like the one WriteRatings writes, for
+// seeding an EliminationTournament. A row with the wrong number of fields,
+// an empty name, a name already rated or a rating that isn't a number is an
+// error, which gives its line.
+func LoadRatings(r io.Reader) (map[string]float64, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	ratings := make(map[string]float64)
+	for first := true; ; first = false {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return ratings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(row) != 2 {
+			return nil, fmt.Errorf("ratings line %d: want 2 fields, got %d", line, len(row))
+		}
+		if first && row[0] == "name" && row[1] == "rating" {
+			continue
+		}
+		if row[0] == "" {
+			return nil, fmt.Errorf("ratings line %d: no strategy name", line)
+		}
+		if _, ok := ratings[row[0]]; ok {
+			return nil, fmt.Errorf("ratings line %d: %q is already rated", line, row[0])
+		}
+		rating, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("ratings line %d: bad rating %q for %q", line, row[1], row[0])
+		}
+		ratings[row[0]] = rating
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+func TestRatingsRoundTrip(t *testing.T) {
+	ratings := map[string]float64{
+		"Stay at 20":        1523.25,
+		"Optimal":           1712.0625,
+		"Random!":           1187.1,
+		"mix(0.5, 0.5)":     1500, // A name that needs quoting
+		"Stay at 25 \"hi\"": 0.1 + 0.2,
+	}
+	var buf strings.Builder
+	if err := WriteRatings(&buf, ratings); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadRatings(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("loading\n%s: %v", buf.String(), err)
+	}
+	if !maps.Equal(got, ratings) {
+		t.Errorf("the ratings came back as %v, want %v", got, ratings)
+	}
+	if lines := strings.Split(buf.String(), "\n"); lines[0] != "name,rating" || !strings.HasPrefix(lines[1], "Optimal,") {
+		t.Errorf("the ratings were written as\n%s\nwant a header, then the highest rated first", buf.String())
+	}
+
+	for _, bad := range []string{
+		"Optimal,1700\nOptimal,1600\n",
+		"Optimal\n",
+		",1500\n",
+		"Optimal,strong\n",
+	} {
+		if _, err := LoadRatings(strings.NewReader(bad)); err == nil {
+			t.Errorf("loading %q succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// A registration describes how to build a strategy from numeric parameters.
+type registration struct {
+	// The parameters, in positional order, each with its valid range and,
+	// as its Value, its value when it is not given.
+	params []Param
+	build  func(p []float64) (Strategy, error)
+}
+
+// The most dice a strategy may roll at once.
+const maxDice = 10
+
+// The range of seeds.
+const minSeed, maxSeed = math.MinInt64, math.MaxInt64
+
+// registry maps strategy names to their registrations.
+var registry = map[string]registration{
+	"stayat": {[]Param{{"k", 1, win, 20}}, func(p []float64) (Strategy, error) {
+		return &StayAtK{int(p[0])}, nil
+	}},
+	"capped": {[]Param{{"k", 1, win, 20}, {"target", 1, win, win}}, func(p []float64) (Strategy, error) {
+		return &CappedStayAtK{int(p[0]), int(p[1])}, nil
+	}},
+	"random": {[]Param{{"seed", minSeed, maxSeed, 0}}, func(p []float64) (Strategy, error) {
+		return NewRandom(int64(p[0])), nil
+	}},
+	"weighted": {[]Param{{"p", 0, 1, 0.5}, {"seed", minSeed, maxSeed, 0}}, func(p []float64) (Strategy, error) {
+		w, err := NewWeightedRandom(p[0], int64(p[1]))
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}},
+	"smartev": {[]Param{{"panic", 0, win, 20}}, func(p []float64) (Strategy, error) {
+		return &SmartEV{int(p[0])}, nil
+	}},
+	"ratiopush": {[]Param{{"ratio", 0, win, 1}}, func(p []float64) (Strategy, error) {
+		return &RatioPush{p[0]}, nil
+	}},
+	"cautious": {[]Param{{"ppt", 1, win, 8}}, func(p []float64) (Strategy, error) {
+		return &Cautious{p[0]}, nil
+	}},
+	"annealing": {[]Param{{"temperature", 0, 1, 0.05}, {"cooling", 0, 1, 0.9}, {"k", 1, win, 20}, {"seed", minSeed, maxSeed, 0}}, func(p []float64) (Strategy, error) {
+		return NewAnnealingStrategy(p[0], p[1], int(p[2]), int64(p[3])), nil
+	}},
+	"lead": {[]Param{{"goal", 1, win, 20}}, func(p []float64) (Strategy, error) {
+		return &LeadTarget{int(p[0])}, nil
+	}},
+	"optimal": {nil, func(p []float64) (Strategy, error) {
+		return &Optimal{}, nil
+	}},
+	"peekoptimal": {nil, func(p []float64) (Strategy, error) {
+		return &PeekOptimal{}, nil
+	}},
+	"maxmargin": {nil, func(p []float64) (Strategy, error) {
+		return &MaxMargin{}, nil
+	}},
+	"probthreshold": {[]Param{{"epsilon", -1, 1, 0}}, func(p []float64) (Strategy, error) {
+		return &ProbThreshold{p[0]}, nil
+	}},
+	"adaptive": {[]Param{{"behind", 1, win, 25}, {"even", 1, win, 20}, {"ahead", 1, win, 15}}, func(p []float64) (Strategy, error) {
+		return &Adaptive{int(p[0]), int(p[1]), int(p[2])}, nil
+	}},
+	"firstmover": {[]Param{{"firstK", 1, win, 19}, {"secondK", 1, win, 21}}, func(p []float64) (Strategy, error) {
+		return &FirstMoverAware{firstK: int(p[0]), secondK: int(p[1])}, nil
+	}},
+	"dice": {[]Param{{"k", 1, win, 20}, {"dice", 1, maxDice, 2}}, func(p []float64) (Strategy, error) {
+		return &StayAtKDice{int(p[0]), int(p[1])}, nil
+	}},
+}
+
+// LookupStrategy returns the strategy described by spec: a registered name
+// followed by colon-separated arguments, each either a value for the next
+// parameter or a name=value pair, e.g. "stayat:20" or "adaptive:behind=30".
+func LookupStrategy(spec string) (Strategy, error) {
+	fields := strings.Split(spec, ":")
+	return buildStrategy(fields[0], fields[1:])
+}
+
+// buildStrategy builds the strategy registered as name from args, each
+// either a value for the next parameter or a name=value pair.
+func buildStrategy(name string, args []string) (Strategy, error) {
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q", name)
+	}
+	p := paramValues(r.params)
+	for i, arg := range args {
+		index := i
+		if eq := strings.Index(arg, "="); eq >= 0 {
+			index = -1
+			for j, param := range r.params {
+				if param.Name == arg[:eq] {
+					index = j
+				}
+			}
+			if index < 0 {
+				return nil, fmt.Errorf("strategy %q has no parameter %q", name, arg[:eq])
+			}
+			arg = arg[eq+1:]
+		}
+		if index >= len(p) {
+			return nil, fmt.Errorf("strategy %q takes %d arguments", name, len(p))
+		}
+		param := r.params[index]
+		v, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return nil, fmt.Errorf("strategy %q: bad value %q for %s", name, arg, param.Name)
+		}
+		if !(v >= param.Min && v <= param.Max) {
+			return nil, fmt.Errorf("strategy %q: %s %g is not between %g and %g", name, param.Name, v, param.Min, param.Max)
+		}
+		p[index] = v
+	}
+	return r.build(p)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A Standing is a strategy's record over a simulation.
+type Standing struct {
+	Strategy     Strategy
+	Wins, Losses int
+	Draws        int // Only possible under some variants
+}
+
+// winRate returns the percentage of its games the strategy won.
+func (self Standing) winRate() float64 {
+	return 100 * float64(self.Wins) / float64(self.Wins+self.Losses+self.Draws)
+}
+
+// MarshalJSON encodes self with the strategy's name and its win rate as a
+// fraction.
+func (self Standing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Strategy string  `json:"strategy"`
+		Wins     int     `json:"wins"`
+		Losses   int     `json:"losses"`
+		Draws    int     `json:"draws"`
+		WinRate  float64 `json:"winRate"`
+	}{self.Strategy.String(), self.Wins, self.Losses, self.Draws, self.winRate() / 100})
+}
+
+// hasDraws reports whether any of standings includes a draw.
+func hasDraws(standings []Standing) bool {
+	for _, st := range standings {
+		if st.Draws > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ranks returns the rank of each of standings by win rate, where 1 is
+// first and standings with equal win rates share a rank.
+func ranks(standings []Standing) []int {
+	r := make([]int, len(standings))
+	for i, st := range standings {
+		r[i] = 1
+		for _, other := range standings {
+			if other.winRate() > st.winRate() {
+				r[i]++
+			}
+		}
+	}
+	return r
+}
+
+// A Reporter writes the standings of a simulation to w.
+type Reporter interface {
+	Report(w io.Writer, standings []Standing) error
+}
+
+// ReportOptions control how Reporters format numbers.
+type ReportOptions struct {
+	Precision int // Decimal places in percentages
+}
+
+// DefaultReportOptions are the options used by the zero value of each
+// Reporter.
+var DefaultReportOptions = ReportOptions{Precision: 1}
+
+// reporters maps the names accepted by the -format flag to constructors of
+// their Reporters.
+var reporters = map[string]func(ReportOptions) Reporter{
+	"text": func(o ReportOptions) Reporter { return TextReporter{&o} },
+	"md":   func(o ReportOptions) Reporter { return MarkdownReporter{&o} },
+	"prom": func(o ReportOptions) Reporter { return PrometheusReporter{} },
+}
+
+// formatPercent formats pct with precision decimal places, always using a
+// period as the decimal separator and never an exponent.
+func formatPercent(pct float64, precision int) string {
+	return strconv.FormatFloat(pct, 'f', precision, 64)
+}
+
+// options returns o, or DefaultReportOptions if o is nil.
+func options(o *ReportOptions) ReportOptions {
+	if o == nil {
+		return DefaultReportOptions
+	}
+	return *o
+}
+
+// TextReporter writes one line of wins and losses per strategy, and of draws
+// too if there were any.
+type TextReporter struct {
+	Options *ReportOptions // If nil, DefaultReportOptions
+}
+
+func (self TextReporter) Report(w io.Writer, standings []Standing) error {
+	o := options(self.Options)
+	draws := hasDraws(standings)
+	for _, st := range standings {
+		var err error
+		if draws {
+			_, err = fmt.Fprintf(w, "Wins, losses, draws %v: %s\n", st.Strategy,
+				ratioStringPrecision(o.Precision, st.Wins, st.Losses, st.Draws))
+		} else {
+			_, err = fmt.Fprintf(w, "Wins, losses %v: %s\n",
+				st.Strategy, ratioStringPrecision(o.Precision, st.Wins, st.Losses))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MarkdownReporter writes the standings as a GitHub-flavored Markdown table,
+// with a column of draws if there were any.
+type MarkdownReporter struct {
+	Options *ReportOptions // If nil, DefaultReportOptions
+}
+
+func (self MarkdownReporter) Report(w io.Writer, standings []Standing) error {
+	o := options(self.Options)
+	draws := hasDraws(standings)
+	header := "| Strategy | Wins | Losses | Win% |\n|---|---:|---:|---:|\n"
+	if draws {
+		header = "| Strategy | Wins | Losses | Draws | Win% |\n|---|---:|---:|---:|---:|\n"
+	}
+	if _, err := fmt.Fprint(w, header); err != nil {
+		return err
+	}
+	for _, st := range standings {
+		name := strings.ReplaceAll(st.Strategy.String(), "|", `\|`)
+		counts := fmt.Sprintf("%d | %d", st.Wins, st.Losses)
+		if draws {
+			counts += fmt.Sprintf(" | %d", st.Draws)
+		}
+		_, err := fmt.Fprintf(w, "| %s | %s | %s%% |\n",
+			name, counts, formatPercent(st.winRate(), o.Precision))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PrometheusReporter writes the standings as metrics in the Prometheus text
+// exposition format, one series per strategy for each metric.
+type PrometheusReporter struct{}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func (PrometheusReporter) Report(w io.Writer, standings []Standing) error {
+	metrics := []struct {
+		name, help string
+		value      func(Standing) float64
+	}{
+		{"pig_strategy_wins", "Games won by the strategy.",
+			func(st Standing) float64 { return float64(st.Wins) }},
+		{"pig_strategy_losses", "Games lost by the strategy.",
+			func(st Standing) float64 { return float64(st.Losses) }},
+		{"pig_strategy_draws", "Games the strategy drew.",
+			func(st Standing) float64 { return float64(st.Draws) }},
+		{"pig_strategy_win_rate", "Fraction of its games the strategy won.",
+			func(st Standing) float64 { return st.winRate() / 100 }},
+	}
+	for _, m := range metrics {
+		_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", m.name, m.help, m.name)
+		if err != nil {
+			return err
+		}
+		for _, st := range standings {
+			_, err := fmt.Fprintf(w, "%s{strategy=\"%s\"} %s\n", m.name,
+				labelEscaper.Replace(st.Strategy.String()),
+				strconv.FormatFloat(m.value(st), 'g', -1, 64))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WritePrometheus writes the standings of r to w in the Prometheus text
+// exposition format.
+func WritePrometheus(w io.Writer, r SimResult) error {
+	return PrometheusReporter{}.Report(w, r.Standings)
+}
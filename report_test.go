@@ -0,0 +1,142 @@
+package main
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// markdownCells splits a Markdown table row into its cells, or returns nil
+// if line is not a row.
+func markdownCells(line string) []string {
+	if !strings.HasPrefix(line, "| ") || !strings.HasSuffix(line, " |") {
+		return nil
+	}
+	cells := regexp.MustCompile(` \| `).Split(line[2:len(line)-2], -1)
+	for _, cell := range cells {
+		if strings.Contains(strings.ReplaceAll(cell, `\|`, ""), "|") {
+			return nil
+		}
+	}
+	return cells
+}
+
+func TestMarkdownReporterWritesATable(t *testing.T) {
+	for _, standings := range [][]Standing{
+		{{&StayAtK{20}, 60, 40, 0}, {&StayAtK{25}, 40, 60, 0}, {&Optimal{}, 55, 45, 0}},
+		{{&StayAtK{20}, 60, 30, 10}, {&StayAtK{25}, 30, 60, 10}},
+	} {
+		var b strings.Builder
+		if err := (MarkdownReporter{}).Report(&b, standings); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		if len(lines) < 2 {
+			t.Fatalf("got %q, want a header and a separator", b.String())
+		}
+		header := markdownCells(lines[0])
+		if header == nil || header[0] != "Strategy" {
+			t.Fatalf("header row %q is not a table row starting with Strategy", lines[0])
+		}
+		separator := regexp.MustCompile(`^\|(-{3,}\||-{3,}:\|)+$`)
+		if !separator.MatchString(lines[1]) || strings.Count(lines[1], "|") != len(header)+1 {
+			t.Errorf("separator row %q doesn't fit the %d columns of the header", lines[1], len(header))
+		}
+		rows := lines[2:]
+		if len(rows) != len(standings) {
+			t.Errorf("got %d data rows, want one per standing, %d", len(rows), len(standings))
+		}
+		for i, row := range rows {
+			cells := markdownCells(row)
+			if len(cells) != len(header) {
+				t.Errorf("row %q has %d cells, want %d", row, len(cells), len(header))
+				continue
+			}
+			if cells[0] != standings[i].Strategy.String() {
+				t.Errorf("row %d is of %q, want %v", i, cells[0], standings[i].Strategy)
+			}
+		}
+	}
+}
+
+func TestReportersUsePrecision(t *testing.T) {
+	standings := []Standing{{&StayAtK{20}, 2, 1, 0}, {&StayAtK{25}, 1, 2, 0}}
+	o := ReportOptions{Precision: 3}
+	for name, r := range map[string]Reporter{"text": TextReporter{&o}, "md": MarkdownReporter{&o}} {
+		var b strings.Builder
+		if err := r.Report(&b, standings); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"66.667%", "33.333%"} {
+			if !strings.Contains(b.String(), want) {
+				t.Errorf("%s output %q doesn't contain %q", name, b.String(), want)
+			}
+		}
+		if regexp.MustCompile(`\d,\d`).MatchString(b.String()) {
+			t.Errorf("%s output %q uses a comma as a decimal separator", name, b.String())
+		}
+	}
+	for _, test := range []struct {
+		pct       float64
+		precision int
+		want      string
+	}{{50, 0, "50"}, {12.5, 1, "12.5"}, {1e-7, 3, "0.000"}, {100, 2, "100.00"}} {
+		if got := formatPercent(test.pct, test.precision); got != test.want {
+			t.Errorf("formatPercent(%g, %d) = %q, want %q", test.pct, test.precision, got, test.want)
+		}
+	}
+}
+
+// named plays like StayAtK{20} under an arbitrary name.
+type named string
+
+func (self named) nextAction(s score) action {
+	return (&StayAtK{20}).nextAction(s)
+}
+
+func (self named) String() string {
+	return string(self)
+}
+
+func TestPrometheusReporterWritesValidText(t *testing.T) {
+	names := []string{"Stay at 20", `odd "quoted" \ name`, "two\nlines"}
+	var standings []Standing
+	for i, name := range names {
+		standings = append(standings, Standing{named(name), 10 + i, 5, 1})
+	}
+	var b strings.Builder
+	if err := (PrometheusReporter{}).Report(&b, standings); err != nil {
+		t.Fatal(err)
+	}
+	sample := regexp.MustCompile(`^(pig_[a-z_]+)\{strategy="((?:[^"\\\n]|\\[\\"n])*)"\} (\S+)$`)
+	unescape := strings.NewReplacer(`\\`, `\`, `\"`, `"`, `\n`, "\n")
+	series := make(map[string][]string)
+	var metric string
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, "# HELP ") {
+			metric = strings.Fields(line)[2]
+			if i+1 >= len(lines) || lines[i+1] != "# TYPE "+metric+" gauge" {
+				t.Errorf("HELP for %s isn't followed by its TYPE", metric)
+			}
+			i++
+			continue
+		}
+		m := sample.FindStringSubmatch(line)
+		if m == nil || m[1] != metric {
+			t.Errorf("line %q is not a sample of %s", line, metric)
+			continue
+		}
+		series[metric] = append(series[metric], unescape.Replace(m[2]))
+	}
+	if len(series) != 4 {
+		t.Errorf("got metrics %v, want 4", series)
+	}
+	for metric, strategies := range series {
+		if !slices.Equal(strategies, names) {
+			t.Errorf("%s has series for %q, want one for each of %q", metric, strategies, names)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+// A Scorer awards tournament points for the result of a series, keyed by
+// strategy name. A strategy missing from the map gets no points.
+type Scorer interface {
+	Score(result MatchResult) map[string]float64
+}
+
+// A ScorerFunc is a function used as a Scorer.
+type ScorerFunc func(result MatchResult) map[string]float64
+
+func (f ScorerFunc) Score(result MatchResult) map[string]float64 {
+	return f(result)
+}
+
+// WinScorer awards a point per game won.
+type WinScorer struct{}
+
+func (WinScorer) Score(result MatchResult) map[string]float64 {
+	return map[string]float64{
+		result.A.String(): float64(result.WinsA),
+		result.B.String(): float64(result.WinsB),
+	}
+}
+
+// A ScoredStanding is a strategy's record over a round robin, with the
+// points it was awarded.
+type ScoredStanding struct {
+	Standing
+	Points float64
+}
+
+// ScoredRoundRobin plays the same series as Simulate and returns each
+// strategy's standing, with its points totalled over the series by scorer,
+// or by WinScorer if scorer is nil. Strategies with the same name share
+// their points.
+func ScoredRoundRobin(strategies []Strategy, games int, seed int64, scorer Scorer) []ScoredStanding {
+	if scorer == nil {
+		scorer = WinScorer{}
+	}
+	seed = resolveSeed(seed)
+	standings := make([]ScoredStanding, len(strategies))
+	points := make(map[string]float64)
+	players := cloneAll(strategies)
+	for i := range players {
+		standings[i].Strategy = strategies[i]
+		for j := i + 1; j < len(players); j++ {
+			a, b, series := pairSeries(seed, players, i, j)
+			c := seededSeries(players[a], players[b], games, series)
+			standings[a].Wins += c.WinsA
+			standings[a].Losses += c.WinsB
+			standings[a].Draws += c.Draws
+			standings[b].Wins += c.WinsB
+			standings[b].Losses += c.WinsA
+			standings[b].Draws += c.Draws
+			for name, p := range scorer.Score(MatchResult{strategies[a], strategies[b], a, b, c.WinsA, c.WinsB}) {
+				points[name] += p
+			}
+		}
+	}
+	for i := range standings {
+		standings[i].Points = points[strategies[i].String()]
+	}
+	return standings
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestScoredRoundRobinUsesTheScorer(t *testing.T) {
+	lineup := []Strategy{&StayAtK{15}, &StayAtK{20}, &StayAtK{25}, &Random{}}
+	threePerWin := ScorerFunc(func(result MatchResult) map[string]float64 {
+		return map[string]float64{
+			result.A.String(): 3 * float64(result.WinsA),
+			result.B.String(): 3 * float64(result.WinsB),
+		}
+	})
+	standings := ScoredRoundRobin(lineup, 200, 1, threePerWin)
+	plain := ScoredRoundRobin(lineup, 200, 1, nil)
+	want := Simulate(lineup, 200, 1).Standings
+	for i, st := range standings {
+		if st.Strategy != lineup[i] || st.Wins != want[i].Wins || st.Losses != want[i].Losses {
+			t.Errorf("standing %d is %v %d-%d, want %v %d-%d as by Simulate",
+				i, st.Strategy, st.Wins, st.Losses, want[i].Strategy, want[i].Wins, want[i].Losses)
+		}
+		if st.Points != 3*float64(st.Wins) {
+			t.Errorf("%v won %d games and was awarded %g points, want 3 a win", st.Strategy, st.Wins, st.Points)
+		}
+		if plain[i].Points != float64(st.Wins) {
+			t.Errorf("%v won %d games and was awarded %g points by default, want 1 a win", st.Strategy, st.Wins, plain[i].Points)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// PlaySeries simulates games Pig games between a and b and returns the
+// winner (0 for a, 1 for b, or Draw) of each game in order. The players
+// alternate moving first, starting with a, and the dice are seeded with
+// seed.
+func PlaySeries(a, b Strategy, games int, seed int64) []int {
+	winners, _ := GameConfig{}.PlaySeries(a, b, games, seed)
+	return winners
+}
+
+// PlaySeries is like the package function PlaySeries, but plays by the rules
+// of cfg, including who moves first. It returns an error if cfg is invalid.
+func (cfg GameConfig) PlaySeries(a, b Strategy, games int, seed int64) ([]int, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	weaker := -1
+	if cfg.Start == StartWeaker {
+		ratingA, okA := cfg.Ratings[a.String()]
+		ratingB, okB := cfg.Ratings[b.String()]
+		switch {
+		case !okA || !okB:
+			return nil, fmt.Errorf("StartWeaker needs ratings of both %v and %v", a, b)
+		case ratingA < ratingB:
+			weaker = 0
+		case ratingB < ratingA:
+			weaker = 1
+		}
+	}
+	rng := rand.New(rand.NewSource(seed))
+	dice := cfg.die(rng)
+	winners := make([]int, games)
+	first := 0
+	for i := range winners {
+		switch {
+		case weaker >= 0:
+			first = weaker
+		case cfg.Start == StartRandom:
+			first = rng.Intn(2)
+		case cfg.Start == StartLoserFirst && i > 0 && winners[i-1] != Draw:
+			first = 1 - winners[i-1]
+		case i > 0:
+			first = 1 - first
+		}
+		winners[i] = playGame(cfg, a, b, first, dice, nil)
+	}
+	return winners, nil
+}
+
+// PlayDetailed simulates a Pig game between a and b in which player first
+// moves first, with dice seeded with seed, and returns how it ended.
+func PlayDetailed(a, b Strategy, first int, seed int64) GameDetail {
+	dice := GameConfig{}.die(rand.New(rand.NewSource(seed)))
+	return playDetailed(GameConfig{}, a, b, first, dice, nil)
+}
+
+// PlayFromState simulates a Pig game between s0 and s1 that starts at
+// initial, with player firstPlayer to move, rolling with rng. initial is from
+// firstPlayer's point of view, and may include points already rolled this
+// turn. It returns the winner (0 or 1), or an error if initial is not a
+// position the game can be in before it is won.
+func PlayFromState(s0, s1 Strategy, initial score, firstPlayer int, rng *rand.Rand) (int, error) {
+	if initial.player < 0 || initial.opponent < 0 || initial.thisTurn < 0 ||
+		initial.opponent >= win || initial.player+initial.thisTurn >= win {
+		return 0, fmt.Errorf("%+v is not a position in a game in progress", initial)
+	}
+	if firstPlayer != 0 && firstPlayer != 1 {
+		return 0, fmt.Errorf("player %d is not 0 or 1", firstPlayer)
+	}
+	return playFrom(GameConfig{}, s0, s1, firstPlayer, initial, GameConfig{}.die(rng), nil).Winner, nil
+}
+
+// A Comparison is the tally of a series of games between two strategies.
+type Comparison struct {
+	Games, WinsA, WinsB int
+	Draws               int // Only possible under some variants
+}
+
+// winRateA returns the fraction of the games that A won.
+func (self Comparison) winRateA() float64 {
+	return float64(self.WinsA) / float64(self.Games)
+}
+
+// EWMAWinRate returns the fraction of the games in sequence, a list of
+// winners as returned by PlaySeries, that forPlayer won, with each game's
+// weight halving every halfLife games back from the last, or 0 if sequence
+// is empty, since forPlayer has won nothing. halfLife must be positive.
+func EWMAWinRate(sequence []int, halfLife int, forPlayer int) float64 {
+	if len(sequence) == 0 {
+		return 0
+	}
+	decay := math.Pow(0.5, 1/float64(halfLife))
+	won, total, weight := 0.0, 0.0, 1.0
+	for i := len(sequence) - 1; i >= 0; i-- {
+		if sequence[i] == forPlayer {
+			won += weight
+		}
+		total += weight
+		weight *= decay
+	}
+	return won / total
+}
+
+// LongestStreak returns the length of the longest run of consecutive games
+// in sequence, a list of winners as returned by PlaySeries, that forPlayer
+// won.
+func LongestStreak(sequence []int, forPlayer int) int {
+	longest, streak := 0, 0
+	for _, winner := range sequence {
+		if winner != forPlayer {
+			streak = 0
+			continue
+		}
+		streak++
+		longest = max(longest, streak)
+	}
+	return longest
+}
+
+// A ConvergencePoint is a's win rate, as a fraction, over the first Games
+// games of a series.
+type ConvergencePoint struct {
+	Games   int
+	WinRate float64
+}
+
+// ConvergenceCurve plays the same series of maxGames games as PlaySeries
+// and returns a's running win rate after every step games, so that the
+// last point, if step divides maxGames, is its win rate over the whole
+// series. step must be positive.
+func ConvergenceCurve(a, b Strategy, maxGames, step int, seed int64) []ConvergencePoint {
+	var curve []ConvergencePoint
+	won := 0
+	for i, winner := range PlaySeries(a, b, maxGames, seed) {
+		if winner == 0 {
+			won++
+		}
+		if (i+1)%step == 0 {
+			curve = append(curve, ConvergencePoint{i + 1, float64(won) / float64(i+1)})
+		}
+	}
+	return curve
+}
+
+// CompareStrategies simulates the same series of games as PlaySeries and
+// returns how many each strategy won.
+func CompareStrategies(a, b Strategy, games int, seed int64) Comparison {
+	c, _ := GameConfig{}.CompareStrategies(a, b, games, seed)
+	return c
+}
+
+// CompareStrategies is like the package function CompareStrategies, but
+// plays by the rules of cfg. It returns an error if cfg is invalid.
+func (cfg GameConfig) CompareStrategies(a, b Strategy, games int, seed int64) (Comparison, error) {
+	winners, err := cfg.PlaySeries(a, b, games, seed)
+	if err != nil {
+		return Comparison{}, err
+	}
+	c := Comparison{Games: games}
+	for _, winner := range winners {
+		switch winner {
+		case 0:
+			c.WinsA++
+		case 1:
+			c.WinsB++
+		default:
+			c.Draws++
+		}
+	}
+	return c, nil
+}
+
+// BatchCompare plays a series of games games between each of pairs and
+// returns the comparisons in order. The k-th pair's dice are seeded with
+// baseSeed+k, so its result is CompareStrategies(a, b, games, baseSeed+k);
+// if either strategy is a Seeder, it is first reseeded from a separate
+// source, also seeded with baseSeed+k. The pairs are compared concurrently,
+// up to one per CPU at a time, each by clones of its strategies so that
+// pairs can share them. The clones are made before any series starts, so
+// the results only depend on the pairs and baseSeed.
+func BatchCompare(pairs [][2]Strategy, games int, baseSeed int64) []Comparison {
+	type job struct {
+		k    int
+		a, b Strategy
+	}
+	results := make([]Comparison, len(pairs))
+	next := make(chan job)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range next {
+				seed := baseSeed + int64(j.k)
+				reseed(j.a, j.b, seed)
+				results[j.k] = CompareStrategies(j.a, j.b, games, seed)
+			}
+		}()
+	}
+	for k, pair := range pairs {
+		next <- job{k, clone(pair[0]), clone(pair[1])}
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// A CachedEvaluator runs CompareStrategies, remembering each result so that
+// asking for the same comparison again costs nothing. Comparisons are
+// identified by the two strategies' names, the number of games and the
+// seed, so it must not be used with strategies whose names don't identify
+// how they play. The zero value is ready to use, and it is safe for
+// concurrent use.
+type CachedEvaluator struct {
+	mu    sync.Mutex
+	cache map[comparisonKey]Comparison
+}
+
+// A comparisonKey identifies a comparison for a CachedEvaluator.
+type comparisonKey struct {
+	a, b  string
+	games int
+	seed  int64
+}
+
+// Compare returns CompareStrategies(a, b, games, seed), from the cache if
+// it has been asked for before.
+func (self *CachedEvaluator) Compare(a, b Strategy, games int, seed int64) Comparison {
+	key := comparisonKey{a.String(), b.String(), games, seed}
+	self.mu.Lock()
+	c, ok := self.cache[key]
+	self.mu.Unlock()
+	if ok {
+		return c
+	}
+	c = CompareStrategies(a, b, games, seed)
+	self.mu.Lock()
+	if self.cache == nil {
+		self.cache = make(map[comparisonKey]Comparison)
+	}
+	self.cache[key] = c
+	self.mu.Unlock()
+	return c
+}
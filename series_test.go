@@ -0,0 +1,261 @@
+package main
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlaySeriesMatchesCompareStrategies(t *testing.T) {
+	const games = 500
+	winners := PlaySeries(&StayAtK{20}, &StayAtK{25}, games, 7)
+	if len(winners) != games {
+		t.Fatalf("got %d winners, want %d", len(winners), games)
+	}
+	var got Comparison
+	got.Games = games
+	for _, w := range winners {
+		switch w {
+		case 0:
+			got.WinsA++
+		case 1:
+			got.WinsB++
+		default:
+			got.Draws++
+		}
+	}
+	if want := CompareStrategies(&StayAtK{20}, &StayAtK{25}, games, 7); got != want {
+		t.Errorf("PlaySeries totals %+v, CompareStrategies gives %+v", got, want)
+	}
+}
+
+func TestMaxTurnsForcesDraws(t *testing.T) {
+	// Nobody can score 100 in two turns by staying at 20.
+	cfg := GameConfig{MaxTurns: 2}
+	winners, err := cfg.PlaySeries(&StayAtK{20}, &StayAtK{25}, 20, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range winners {
+		if w != Draw {
+			t.Fatalf("game %d ended with winner %d, want Draw", i, w)
+		}
+	}
+	c, _ := cfg.CompareStrategies(&StayAtK{20}, &StayAtK{25}, 20, 1)
+	if c != (Comparison{Games: 20, Draws: 20}) {
+		t.Errorf("got %+v, want 20 draws", c)
+	}
+	standings := []Standing{{&StayAtK{20}, 0, 0, 20}, {&StayAtK{25}, 0, 0, 20}}
+	var b strings.Builder
+	if err := (MarkdownReporter{}).Report(&b, standings); err != nil {
+		t.Fatal(err)
+	}
+	header, _, _ := strings.Cut(b.String(), "\n")
+	if want := "| Strategy | Wins | Losses | Draws | Win% |"; header != want {
+		t.Errorf("header %q, want %q", header, want)
+	}
+	if !strings.Contains(b.String(), "| Stay at 20 | 0 | 0 | 20 | 0.0% |") {
+		t.Errorf("%q doesn't report the draws", b.String())
+	}
+}
+
+func TestEWMAWinRateWeighsRecentGames(t *testing.T) {
+	// Losing the first 60 games and winning the last 40 averages 40%.
+	sequence := make([]int, 100)
+	for i := range sequence {
+		if i < 60 {
+			sequence[i] = 1
+		}
+	}
+	if rate := EWMAWinRate(sequence, 10, 0); rate < 0.9 {
+		t.Errorf("after 40 straight wins the EWMA win rate is %.3f, want at least 0.9", rate)
+	}
+	if rate := EWMAWinRate(sequence, 10, 1); rate > 0.1 {
+		t.Errorf("after 40 straight losses the EWMA win rate is %.3f, want at most 0.1", rate)
+	}
+	if rate := EWMAWinRate(nil, 10, 0); rate != 0 {
+		t.Errorf("with no games the EWMA win rate is %g, want 0", rate)
+	}
+}
+
+// starts plays like StayAtK{k} and records whether it moved first in each
+// game.
+type starts struct {
+	StayAtK
+	first []bool
+}
+
+func (self *starts) SetStartingPlayer(first bool) {
+	self.first = append(self.first, first)
+}
+
+func TestStartLoserFirst(t *testing.T) {
+	a, b := &starts{StayAtK: StayAtK{20}}, &starts{StayAtK: StayAtK{25}}
+	winners, err := GameConfig{Start: StartLoserFirst}.PlaySeries(a, b, 200, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.first) != len(winners) || len(b.first) != len(winners) {
+		t.Fatalf("told a and b who started %d and %d times in %d games", len(a.first), len(b.first), len(winners))
+	}
+	for i := range winners {
+		if a.first[i] == b.first[i] {
+			t.Fatalf("game %d: a first=%v and b first=%v", i, a.first[i], b.first[i])
+		}
+		want := i == 0 || winners[i-1] == 1 // a starts the first game, then whenever it lost
+		if a.first[i] != want {
+			t.Errorf("game %d: a moved first=%v after winner %d, want %v", i, a.first[i], winners[max(i-1, 0)], want)
+		}
+	}
+}
+
+func TestBatchCompareMatchesSeriesPlayedOneByOne(t *testing.T) {
+	shared := NewRandom(1)
+	pairs := [][2]Strategy{
+		{&StayAtK{20}, &StayAtK{25}},
+		{shared, &StayAtK{20}},
+		{&Optimal{}, shared},
+		{&StayAtK{20}, &StayAtK{25}},
+	}
+	got := BatchCompare(pairs, 300, 10)
+	for k, pair := range pairs {
+		seed := 10 + int64(k)
+		a, b := clone(pair[0]), clone(pair[1])
+		if pair[0] == shared || pair[1] == shared {
+			// The batch reseeds Seeders, from a source of their own.
+			reseed(a, b, seed)
+		}
+		if want := CompareStrategies(a, b, 300, seed); got[k] != want {
+			t.Errorf("pair %d: BatchCompare gave %+v, CompareStrategies gave %+v", k, got[k], want)
+		}
+	}
+	if got[0] == got[3] {
+		t.Errorf("the same pair with different seeds still gave the same result %+v", got[0])
+	}
+}
+
+func TestLongestStreak(t *testing.T) {
+	for _, test := range []struct {
+		sequence []int
+		want     int
+	}{
+		{[]int{0, 0, 1, 0, 0, 0, 1, 0}, 3},
+		{[]int{0, 1, Draw, 0, 0}, 2},
+		{[]int{0, 0, 0, 0}, 4},
+		{[]int{1, 1, 1}, 0},
+		{nil, 0},
+	} {
+		if got := LongestStreak(test.sequence, 0); got != test.want {
+			t.Errorf("LongestStreak(%v, 0) = %d, want %d", test.sequence, got, test.want)
+		}
+	}
+	if got := LongestStreak([]int{1, 1, 1}, 1); got != 3 {
+		t.Errorf("player 1's streak in three straight wins is %d, want 3", got)
+	}
+}
+
+func TestCachedEvaluatorRemembersComparisons(t *testing.T) {
+	var cache CachedEvaluator
+	a := &CountingStrategy{strategy: &StayAtK{20}}
+	b := &StayAtK{25}
+	first := cache.Compare(a, b, 200, 1)
+	if want := CompareStrategies(&StayAtK{20}, b, 200, 1); first != want {
+		t.Errorf("the first comparison was %+v, want %+v", first, want)
+	}
+	decisions := a.Rolls + a.Stays
+	if again := cache.Compare(a, b, 200, 1); again != first || a.Rolls+a.Stays != decisions {
+		t.Errorf("asking again gave %+v after %d more decisions, want %+v from the cache",
+			again, a.Rolls+a.Stays-decisions, first)
+	}
+	for _, miss := range []struct {
+		b     Strategy
+		games int
+		seed  int64
+	}{{&StayAtK{30}, 200, 1}, {b, 300, 1}, {b, 200, 2}} {
+		decisions := a.Rolls + a.Stays
+		got := cache.Compare(a, miss.b, miss.games, miss.seed)
+		if a.Rolls+a.Stays == decisions {
+			t.Errorf("comparing against %v over %d games seeded with %d was served from the cache", miss.b, miss.games, miss.seed)
+		}
+		if want := CompareStrategies(&StayAtK{20}, miss.b, miss.games, miss.seed); got != want {
+			t.Errorf("comparing against %v over %d games seeded with %d gave %+v, want %+v",
+				miss.b, miss.games, miss.seed, got, want)
+		}
+	}
+}
+
+func TestPlayFromStateNearAWin(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, first := range []int{0, 1} {
+		won := 0
+		for game := 0; game < 1000; game++ {
+			// The player to move has 95 to their opponent's 10.
+			winner, err := PlayFromState(&StayAtK{20}, &StayAtK{20}, score{95, 10, 0}, first, rng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if winner == first {
+				won++
+			}
+		}
+		if won < 900 {
+			t.Errorf("player %d won %d of 1000 games from 95 to 10, want nearly all", first, won)
+		}
+	}
+	for _, s := range []score{{100, 10, 0}, {10, 100, 0}, {90, 10, 10}, {-1, 0, 0}, {0, 0, -2}} {
+		if _, err := PlayFromState(&StayAtK{20}, &StayAtK{20}, s, 0, rng); err == nil {
+			t.Errorf("playing from %+v, which isn't a game in progress, succeeded", s)
+		}
+	}
+	if _, err := PlayFromState(&StayAtK{20}, &StayAtK{20}, score{}, 2, rng); err == nil {
+		t.Error("playing with player 2 to move succeeded")
+	}
+}
+
+func TestConvergenceCurveEndsAtTheSeriesWinRate(t *testing.T) {
+	a, b := &StayAtK{20}, &StayAtK{30}
+	curve := ConvergenceCurve(a, b, 1000, 50, 1)
+	if len(curve) != 1000/50 {
+		t.Fatalf("the curve has %d points, want %d", len(curve), 1000/50)
+	}
+	for i, p := range curve {
+		if p.Games != 50*(i+1) || p.WinRate < 0 || p.WinRate > 1 {
+			t.Errorf("point %d of the curve is %+v", i, p)
+		}
+	}
+	if last, want := curve[len(curve)-1].WinRate, CompareStrategies(a, b, 1000, 1).winRateA(); last != want {
+		t.Errorf("the curve ends at %f, want %f from CompareStrategies", last, want)
+	}
+	if n := len(ConvergenceCurve(a, b, 1000, 300, 1)); n != 3 {
+		t.Errorf("a curve over 1000 games every 300 has %d points, want 3", n)
+	}
+}
+
+func TestStartWeaker(t *testing.T) {
+	ratings := map[string]float64{"Stay at 20": 1550, "Stay at 25": 1450}
+	for _, order := range [][2]int{{20, 25}, {25, 20}} {
+		a, b := &starts{StayAtK: StayAtK{order[0]}}, &starts{StayAtK: StayAtK{order[1]}}
+		if _, err := (GameConfig{Start: StartWeaker, Ratings: ratings}).PlaySeries(a, b, 50, 1); err != nil {
+			t.Fatal(err)
+		}
+		weaker := a
+		if order[1] == 25 {
+			weaker = b
+		}
+		if len(weaker.first) != 50 || slices.Contains(weaker.first, false) {
+			t.Errorf("with %v vs %v, the lower rated %v moved first %v", a, b, weaker, weaker.first)
+		}
+	}
+	ratings["Stay at 25"] = 1550
+	a, b := &starts{StayAtK: StayAtK{20}}, &starts{StayAtK: StayAtK{25}}
+	if _, err := (GameConfig{Start: StartWeaker, Ratings: ratings}).PlaySeries(a, b, 4, 1); err != nil {
+		t.Fatal(err)
+	}
+	if want := []bool{true, false, true, false}; !slices.Equal(a.first, want) {
+		t.Errorf("between equal ratings %v moved first %v, want %v", a, a.first, want)
+	}
+	if _, err := (GameConfig{Start: StartWeaker, Ratings: ratings}).PlaySeries(a, &StayAtK{30}, 4, 1); err == nil {
+		t.Error("StartWeaker played a strategy without a rating")
+	}
+}
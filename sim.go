@@ -0,0 +1,522 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+)
+
+// SimOptions control optional behavior of a simulation.
+type SimOptions struct {
+	// Logger receives structured logs of the simulation's progress: its
+	// start and summary at info level, and each series at debug level. If
+	// nil, nothing is logged.
+	Logger *slog.Logger
+}
+
+// A SimOption sets one of the SimOptions.
+type SimOption func(*SimOptions)
+
+// WithLogger logs the simulation's progress to logger.
+func WithLogger(logger *slog.Logger) SimOption {
+	return func(o *SimOptions) {
+		o.Logger = logger
+	}
+}
+
+// newSimOptions applies opts to the default SimOptions.
+func newSimOptions(opts []SimOption) SimOptions {
+	var o SimOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Logger == nil {
+		o.Logger = slog.New(slog.DiscardHandler)
+	}
+	return o
+}
+
+// seriesSeed returns the seed for the series between strategies i and j in
+// a round robin seeded with seed.
+func seriesSeed(seed int64, i, j int) int64 {
+	return seed ^ int64(i)<<32 ^ int64(j)
+}
+
+// pairSeries returns the order in which strategies i and j play their
+// series in a round robin seeded with seed, and the series' seed. Both
+// depend only on seed and the two strategies' names, not on where they are
+// in the lineup, so that reordering a lineup doesn't change any series.
+func pairSeries(seed int64, strategies []Strategy, i, j int) (a, b int, series int64) {
+	if strategies[j].String() < strategies[i].String() {
+		i, j = j, i
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%s\x00%s", seed, strategies[i], strategies[j])
+	return i, j, int64(h.Sum64())
+}
+
+// seededSeries plays a series of games games between a and b, seeded with
+// seed: a and b, if they are Seeders, are reseeded from it, as are the dice.
+func seededSeries(a, b Strategy, games int, seed int64) Comparison {
+	return CompareStrategies(a, b, games, reseed(a, b, seed).Int63())
+}
+
+// reseed prepares a and b for a series seeded with seed: it reseeds them,
+// if they are Seeders, from a source seeded with seed, and resets them. It
+// returns the source, for the rest of the series to draw from.
+func reseed(a, b Strategy, seed int64) *rand.Rand {
+	rng := rand.New(rand.NewSource(seed))
+	for _, s := range []Strategy{a, b} {
+		if seeder, ok := s.(Seeder); ok {
+			seeder.Seed(rng.Int63())
+		}
+	}
+	reset(a, b)
+	return rng
+}
+
+// seededRoundRobin is like roundRobin, but plays games games per series and
+// seeds each series deterministically from seed, as for pairSeries, so that
+// the results only depend on the strategies and seed and not on their
+// order. It logs each series to logger.
+func seededRoundRobin(strategies []Strategy, games int, seed int64, logger *slog.Logger) ([]int, int) {
+	wins := make([]int, len(strategies))
+	results := make(chan []int)
+	for i := 0; i < len(strategies); i++ {
+		go func(i int, players []Strategy) {
+			winCount := make([]int, len(players))
+			for j := i + 1; j < len(players); j++ {
+				a, b, series := pairSeries(seed, players, i, j)
+				c := seededSeries(players[a], players[b], games, series)
+				winCount[a] += c.WinsA
+				winCount[b] += c.WinsB
+				logger.Debug("series finished",
+					"a", players[a].String(), "b", players[b].String(),
+					"winsA", c.WinsA, "winsB", c.WinsB)
+			}
+			results <- winCount
+		}(i, cloneAll(strategies))
+	}
+	for i := 0; i < len(strategies); i++ {
+		r := <-results
+		for j := range r {
+			wins[j] += r[j]
+		}
+	}
+	gamesPerStrategy := games * (len(strategies) - 1) // no self play
+	return wins, gamesPerStrategy
+}
+
+// A RepeatedStat summarizes a strategy's win rate over repeated simulations.
+type RepeatedStat struct {
+	Mean, StdDev float64
+}
+
+// RepeatedSimulation runs a seeded round robin of games games per series
+// repeats times, the r-th seeded with baseSeed+r, and returns the mean and
+// sample standard deviation of each strategy's win rate across the repeats.
+func RepeatedSimulation(strategies []Strategy, games, repeats int, baseSeed int64) []RepeatedStat {
+	rates := make([][]float64, len(strategies))
+	for r := 0; r < repeats; r++ {
+		wins, total := seededRoundRobin(strategies, games, baseSeed+int64(r), newSimOptions(nil).Logger)
+		for i := range strategies {
+			rates[i] = append(rates[i], float64(wins[i])/float64(total))
+		}
+	}
+	stats := make([]RepeatedStat, len(strategies))
+	for i, rs := range rates {
+		stats[i] = meanStdDev(rs)
+	}
+	return stats
+}
+
+// meanStdDev returns the mean and sample standard deviation of xs.
+func meanStdDev(xs []float64) RepeatedStat {
+	var stat RepeatedStat
+	if len(xs) == 0 {
+		return stat
+	}
+	for _, x := range xs {
+		stat.Mean += x
+	}
+	stat.Mean /= float64(len(xs))
+	if len(xs) > 1 {
+		for _, x := range xs {
+			stat.StdDev += (x - stat.Mean) * (x - stat.Mean)
+		}
+		stat.StdDev = math.Sqrt(stat.StdDev / float64(len(xs)-1))
+	}
+	return stat
+}
+
+// A SimResult is the outcome of simulating a round robin of a lineup.
+type SimResult struct {
+	Seed      int64      `json:"seed"`
+	Games     int        `json:"games"` // The number of games played in total
+	Standings []Standing `json:"standings"`
+}
+
+// newSimResult returns the SimResult of a round robin in which strategies
+// won wins of games games each.
+func newSimResult(seed int64, strategies []Strategy, wins []int, games int) SimResult {
+	r := SimResult{Seed: seed, Games: games * len(strategies) / 2}
+	for i, strategy := range strategies {
+		r.Standings = append(r.Standings, Standing{Strategy: strategy, Wins: wins[i], Losses: games - wins[i]})
+	}
+	return r
+}
+
+// A StrategyDelta is how a strategy's standing changed between two
+// simulations. A strategy in only one of them is marked Added or Removed
+// and has no changes.
+type StrategyDelta struct {
+	Name           string
+	WinRate        float64 // The change in win rate, in percentage points
+	Rank           int     // The number of places it rose
+	Added, Removed bool
+}
+
+// DiffResults compares two simulations, matching strategies by name. It
+// returns a delta for each strategy in after, in order, followed by one for
+// each strategy only in before.
+func DiffResults(before, after SimResult) []StrategyDelta {
+	beforeRanks, afterRanks := ranks(before.Standings), ranks(after.Standings)
+	index := make(map[string]int, len(before.Standings))
+	for i, st := range before.Standings {
+		index[st.Strategy.String()] = i
+	}
+	var deltas []StrategyDelta
+	for i, st := range after.Standings {
+		name := st.Strategy.String()
+		j, ok := index[name]
+		if !ok {
+			deltas = append(deltas, StrategyDelta{Name: name, Added: true})
+			continue
+		}
+		delete(index, name)
+		deltas = append(deltas, StrategyDelta{
+			Name:    name,
+			WinRate: st.winRate() - before.Standings[j].winRate(),
+			Rank:    beforeRanks[j] - afterRanks[i],
+		})
+	}
+	for _, st := range before.Standings {
+		if _, ok := index[st.Strategy.String()]; ok {
+			deltas = append(deltas, StrategyDelta{Name: st.Strategy.String(), Removed: true})
+		}
+	}
+	return deltas
+}
+
+// resolveSeed returns seed, or a seed taken from the clock if seed is 0.
+// The result is never 0.
+func resolveSeed(seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+		if seed == 0 {
+			seed = 1
+		}
+	}
+	return seed
+}
+
+// Simulate plays a seeded round robin of games games per series. A seed of 0
+// picks one from the clock; the result records the seed actually used.
+func Simulate(strategies []Strategy, games int, seed int64, opts ...SimOption) SimResult {
+	o := newSimOptions(opts)
+	seed = resolveSeed(seed)
+	o.Logger.Info("simulation started", "strategies", len(strategies), "seed", seed, "games", games)
+	wins, total := seededRoundRobin(strategies, games, seed, o.Logger)
+	r := newSimResult(seed, strategies, wins, total)
+	r.logSummary(o.Logger)
+	return r
+}
+
+// logSummary logs the number of games in r and its leader.
+func (self SimResult) logSummary(logger *slog.Logger) {
+	attrs := []any{"games", self.Games}
+	if len(self.Standings) > 0 {
+		leader := self.Standings[0]
+		for _, st := range self.Standings[1:] {
+			if st.winRate() > leader.winRate() {
+				leader = st
+			}
+		}
+		attrs = append(attrs, "leader", leader.Strategy.String(), "leaderWinRate", leader.winRate()/100)
+	}
+	logger.Info("simulation finished", attrs...)
+}
+
+// SimulateUntil plays seeded round robins of gamesPerSeries games per series,
+// the b-th seeded with seed+b, until deadline has passed, and returns the
+// accumulated result. The deadline is checked before each series, and once
+// it has passed no more are started, so the last round robin may be cut
+// short; the standings count only the games each strategy played. A seed of
+// 0 picks one from the clock, as for Simulate.
+func SimulateUntil(strategies []Strategy, deadline time.Duration, seed int64, opts ...SimOption) SimResult {
+	o := newSimOptions(opts)
+	seed = resolveSeed(seed)
+	o.Logger.Info("simulation started", "strategies", len(strategies), "seed", seed, "deadline", deadline)
+	ctx, cancel := context.WithTimeout(context.Background(), deadline)
+	defer cancel()
+	r := SimResult{Seed: seed, Standings: make([]Standing, len(strategies))}
+	for i := range r.Standings {
+		r.Standings[i].Strategy = strategies[i]
+	}
+	for b := int64(0); ctx.Err() == nil; b++ {
+		standings, games := roundRobinContext(ctx, strategies, gamesPerSeries, seed+b, o.Logger)
+		for i, st := range standings {
+			r.Standings[i].Wins += st.Wins
+			r.Standings[i].Losses += st.Losses
+			r.Standings[i].Draws += st.Draws
+		}
+		r.Games += games
+	}
+	r.logSummary(o.Logger)
+	return r
+}
+
+// roundRobinContext plays the same series as seededRoundRobin, but starts
+// no more once ctx is done. It returns each strategy's record over the
+// series it played, and how many games were played in all.
+func roundRobinContext(ctx context.Context, strategies []Strategy, games int, seed int64, logger *slog.Logger) ([]Standing, int) {
+	type result struct {
+		standings []Standing
+		games     int
+	}
+	results := make(chan result)
+	for i := range strategies {
+		go func(i int, players []Strategy) {
+			r := result{standings: make([]Standing, len(players))}
+			for j := i + 1; j < len(players) && ctx.Err() == nil; j++ {
+				a, b, series := pairSeries(seed, players, i, j)
+				c := seededSeries(players[a], players[b], games, series)
+				r.standings[a].Wins += c.WinsA
+				r.standings[a].Losses += c.WinsB
+				r.standings[a].Draws += c.Draws
+				r.standings[b].Wins += c.WinsB
+				r.standings[b].Losses += c.WinsA
+				r.standings[b].Draws += c.Draws
+				r.games += c.Games
+				logger.Debug("series finished",
+					"a", players[a].String(), "b", players[b].String(),
+					"winsA", c.WinsA, "winsB", c.WinsB)
+			}
+			results <- r
+		}(i, cloneAll(strategies))
+	}
+	standings := make([]Standing, len(strategies))
+	total := 0
+	for range strategies {
+		r := <-results
+		for j, st := range r.standings {
+			standings[j].Wins += st.Wins
+			standings[j].Losses += st.Losses
+			standings[j].Draws += st.Draws
+		}
+		total += r.games
+	}
+	return standings, total
+}
+
+// RoundRobinBudget is like Simulate, but plays totalGames games in all,
+// split as evenly as possible between the series: each of the first
+// totalGames%pairs series, in order of their seeds from pairSeries, plays
+// one more game than the rest. Series are seeded as by Simulate, so the
+// results don't depend on the order of the lineup. Since strategies may
+// then play different numbers of games, the standings count only the games
+// each played.
+func RoundRobinBudget(strategies []Strategy, totalGames int, seed int64) SimResult {
+	seed = resolveSeed(seed)
+	n := len(strategies)
+	pairs := n * (n - 1) / 2
+	var seeds []int64
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			_, _, series := pairSeries(seed, strategies, i, j)
+			seeds = append(seeds, series)
+		}
+	}
+	slices.Sort(seeds)
+	games := func(series int64) int {
+		if pair, _ := slices.BinarySearch(seeds, series); pair < totalGames%pairs {
+			return totalGames/pairs + 1
+		}
+		return totalGames / pairs
+	}
+	results := make(chan []Standing)
+	for i := 0; i < n; i++ {
+		go func(i int, players []Strategy) {
+			standings := make([]Standing, n)
+			for j := i + 1; j < n; j++ {
+				a, b, series := pairSeries(seed, players, i, j)
+				c := seededSeries(players[a], players[b], games(series), series)
+				standings[a].Wins += c.WinsA
+				standings[a].Losses += c.WinsB
+				standings[b].Wins += c.WinsB
+				standings[b].Losses += c.WinsA
+			}
+			results <- standings
+		}(i, cloneAll(strategies))
+	}
+	r := SimResult{Seed: seed, Standings: make([]Standing, n)}
+	for i := range r.Standings {
+		r.Standings[i].Strategy = strategies[i]
+	}
+	for i := 0; i < n; i++ {
+		for j, st := range <-results {
+			r.Standings[j].Wins += st.Wins
+			r.Standings[j].Losses += st.Losses
+		}
+	}
+	if pairs > 0 {
+		r.Games = totalGames
+	}
+	return r
+}
+
+// AuditOrderIndependence reports whether Simulate gives every strategy the
+// same number of wins when the lineup is reversed, as it should: if not,
+// something about a series depends on where its strategies are in the
+// lineup or on when it is played.
+func AuditOrderIndependence(strategies []Strategy, games int, seed int64) bool {
+	seed = resolveSeed(seed)
+	reversed := make([]Strategy, len(strategies))
+	for i, s := range strategies {
+		reversed[len(strategies)-1-i] = s
+	}
+	forward := Simulate(strategies, games, seed).Standings
+	backward := Simulate(reversed, games, seed).Standings
+	for i := range forward {
+		if forward[i].Wins != backward[len(strategies)-1-i].Wins {
+			return false
+		}
+	}
+	return true
+}
+
+// PayoffMatrix plays the same series as Simulate and returns the matrix of
+// win rates, where entry [i][j] is the fraction of its games against
+// strategies j that strategies i won. There is no self play, so each entry
+// on the diagonal is 0.5; otherwise [i][j] + [j][i] is 1 less the fraction
+// of draws.
+func PayoffMatrix(strategies []Strategy, games int, seed int64) [][]float64 {
+	wins := HeadToHead(strategies, games, seed)
+	m := make([][]float64, len(strategies))
+	for i := range m {
+		m[i] = make([]float64, len(strategies))
+		for j := range m[i] {
+			m[i][j] = float64(wins[i][j]) / float64(games)
+		}
+		m[i][i] = 0.5
+	}
+	return m
+}
+
+// HeadToHead plays the same series as Simulate and returns the matrix of
+// wins, where entry [i][j] is how many of its games games against
+// strategies j that strategies i won. Each entry on the diagonal is 0.
+func HeadToHead(strategies []Strategy, games int, seed int64) [][]int {
+	strategies = cloneAll(strategies)
+	m := make([][]int, len(strategies))
+	for i := range m {
+		m[i] = make([]int, len(strategies))
+	}
+	for i := range strategies {
+		for j := i + 1; j < len(strategies); j++ {
+			a, b, series := pairSeries(seed, strategies, i, j)
+			c := seededSeries(strategies[a], strategies[b], games, series)
+			m[a][b] = c.WinsA
+			m[b][a] = c.WinsB
+		}
+	}
+	return m
+}
+
+// DominatedStrategies returns, in order, the indices of the strategies in a
+// matrix of wins over games games per series, as from HeadToHead, that are
+// strictly dominated. Strategy j is dominated by strategy i if i won more
+// than half of its games against j, and against every other strategy k, i
+// won more games than j did. The diagonal is ignored.
+func DominatedStrategies(matrix [][]int, games int) []int {
+	var dominated []int
+	for j := range matrix {
+		for i := range matrix {
+			if i != j && 2*matrix[i][j] > games && dominates(matrix, i, j) {
+				dominated = append(dominated, j)
+				break
+			}
+		}
+	}
+	return dominated
+}
+
+// dominates reports whether strategy i won more games in matrix than
+// strategy j against every strategy other than the two of them.
+func dominates(matrix [][]int, i, j int) bool {
+	for k := range matrix {
+		if k != i && k != j && matrix[i][k] <= matrix[j][k] {
+			return false
+		}
+	}
+	return true
+}
+
+// LineupHash returns a SHA-256 hex digest identifying a simulation of
+// strategies under cfg seeded with seed, for use as a cache key. It depends
+// on each strategy's name, in order, on every field of cfg and on seed.
+func LineupHash(strategies []Strategy, cfg GameConfig, seed int64) string {
+	h := sha256.New()
+	for _, strategy := range strategies {
+		fmt.Fprintf(h, "%q\n", strategy.String())
+	}
+	fmt.Fprintf(h, "%+v\n%d\n", cfg, seed)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RoundRobinSharedDice is like Simulate, but removes luck from the
+// comparison: game g of every series is played with the same sequence of
+// rolls, seeded from seed and g. The rolls are dealt in the order they are
+// made, whichever player makes them, so two pairs see the same rolls for as
+// long as their games go the same way. Players alternate moving first.
+// Each series is played by clones of the strategies, reseeded if they are
+// Seeders from the series' seed, as for pairSeries.
+func RoundRobinSharedDice(strategies []Strategy, games int, seed int64) SimResult {
+	seed = resolveSeed(seed)
+	rolls := make([][]byte, games)
+	sources := make([]*rand.Rand, games)
+	wins := make([]int, len(strategies))
+	players := cloneAll(strategies)
+	for i := range players {
+		for j := i + 1; j < len(players); j++ {
+			_, _, series := pairSeries(seed, players, i, j)
+			reseed(players[i], players[j], series)
+			for g := 0; g < games; g++ {
+				if sources[g] == nil {
+					sources[g] = rand.New(rand.NewSource(seed + int64(g)))
+				}
+				next := 0
+				dice := func() int {
+					if next == len(rolls[g]) {
+						rolls[g] = append(rolls[g], byte(sources[g].Intn(6)+1))
+					}
+					next++
+					return int(rolls[g][next-1])
+				}
+				if playGame(GameConfig{}, players[i], players[j], g%2, dice, nil) == 0 {
+					wins[i]++
+				} else {
+					wins[j]++
+				}
+			}
+		}
+	}
+	return newSimResult(seed, strategies, wins, games*(len(strategies)-1))
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRepeatedSimulationStdDevFallsWithGames(t *testing.T) {
+	lineup := []Strategy{&StayAtK{20}, &StayAtK{25}}
+	few := RepeatedSimulation(lineup, 50, 20, 1)
+	many := RepeatedSimulation(lineup, 2000, 20, 1)
+	for i := range lineup {
+		if many[i].StdDev >= few[i].StdDev/2 {
+			t.Errorf("%v: stddev %.4f over 2000 games, want well under the %.4f over 50",
+				lineup[i], many[i].StdDev, few[i].StdDev)
+		}
+	}
+}
+
+func TestSimulateUntilStopsAtTheDeadline(t *testing.T) {
+	lineup := []Strategy{&StayAtK{15}, &StayAtK{20}, &StayAtK{25}, &StayAtK{30}}
+	const deadline = 50 * time.Millisecond
+	start := time.Now()
+	r := SimulateUntil(lineup, deadline, 1)
+	if elapsed := time.Since(start); elapsed > deadline+time.Second {
+		t.Errorf("returned after %v, want soon after the %v deadline", elapsed, deadline)
+	}
+	if r.Games == 0 || r.Games%gamesPerSeries != 0 {
+		t.Errorf("played %d games, want a positive number of whole series of %d", r.Games, gamesPerSeries)
+	}
+	played := 0
+	for _, st := range r.Standings {
+		played += st.Wins + st.Losses + st.Draws
+	}
+	if played != 2*r.Games {
+		t.Errorf("standings count %d players' games, want two for each of the %d games", played, r.Games)
+	}
+}
+
+func TestRoundRobinBudgetSplitsTheGames(t *testing.T) {
+	played := func(st Standing) int { return st.Wins + st.Losses + st.Draws }
+
+	r := RoundRobinBudget([]Strategy{&StayAtK{20}, &StayAtK{25}}, 37, 1)
+	for _, st := range r.Standings {
+		if played(st) != 37 {
+			t.Errorf("%v played %d games of the only series, want 37", st.Strategy, played(st))
+		}
+	}
+
+	// 62 games between 6 pairs: two series play 11 games and four play 10.
+	lineup := []Strategy{&StayAtK{15}, &StayAtK{20}, &StayAtK{25}, &StayAtK{30}}
+	r = RoundRobinBudget(lineup, 62, 1)
+	if r.Games != 62 {
+		t.Errorf("played %d games, want 62", r.Games)
+	}
+	total := 0
+	for _, st := range r.Standings {
+		if played(st) < 30 || played(st) > 32 {
+			t.Errorf("%v played %d games in 3 series, want 30 to 32", st.Strategy, played(st))
+		}
+		total += played(st)
+	}
+	if total != 2*62 {
+		t.Errorf("the standings count %d players' games, want %d", total, 2*62)
+	}
+}
+
+func TestLineupHashIsStableAndSensitive(t *testing.T) {
+	lineup := func() []Strategy { return []Strategy{&StayAtK{20}, &Optimal{}} }
+	base := LineupHash(lineup(), GameConfig{}, 1)
+	if again := LineupHash(lineup(), GameConfig{}, 1); again != base {
+		t.Errorf("the same simulation hashed to %s and %s", base, again)
+	}
+	if want := hex.EncodedLen(sha256.Size); len(base) != want {
+		t.Errorf("hash %q is %d characters, want %d", base, len(base), want)
+	}
+	if h := LineupHash([]Strategy{&StayAtK{21}, &Optimal{}}, GameConfig{}, 1); h == base {
+		t.Error("changing a strategy's parameter didn't change the hash")
+	}
+	if h := LineupHash([]Strategy{&Optimal{}, &StayAtK{20}}, GameConfig{}, 1); h == base {
+		t.Error("reordering the lineup didn't change the hash")
+	}
+	if h := LineupHash(lineup(), GameConfig{}, 2); h == base {
+		t.Error("changing the seed didn't change the hash")
+	}
+	fields := reflect.TypeOf(GameConfig{})
+	for i := 0; i < fields.NumField(); i++ {
+		var cfg GameConfig
+		field := reflect.ValueOf(&cfg).Elem().Field(i)
+		switch field.Kind() {
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Int, reflect.Int64:
+			field.SetInt(1)
+		case reflect.Map:
+			m := reflect.MakeMap(field.Type())
+			m.SetMapIndex(reflect.Zero(field.Type().Key()), reflect.Zero(field.Type().Elem()))
+			field.Set(m)
+		default:
+			t.Fatalf("no test value for GameConfig.%s", fields.Field(i).Name)
+		}
+		if LineupHash(lineup(), cfg, 1) == base {
+			t.Errorf("setting GameConfig.%s didn't change the hash", fields.Field(i).Name)
+		}
+	}
+}
+
+func TestSimulateRecordsTheSeedItPicks(t *testing.T) {
+	if got := resolveSeed(5); got != 5 {
+		t.Errorf("resolveSeed(5) = %d, want 5", got)
+	}
+	lineup := []Strategy{&StayAtK{20}, &StayAtK{25}, NewRandom(1)}
+	r := Simulate(lineup, 50, 0)
+	if r.Seed == 0 {
+		t.Fatal("Simulate with seed 0 recorded seed 0, want the seed it picked")
+	}
+	again := Simulate([]Strategy{&StayAtK{20}, &StayAtK{25}, NewRandom(1)}, 50, r.Seed)
+	if !reflect.DeepEqual(again.Standings, r.Standings) {
+		t.Errorf("rerunning with the recorded seed %d gave %v, want %v", r.Seed, again.Standings, r.Standings)
+	}
+}
+
+func TestRoundRobinSharedDiceReducesVariance(t *testing.T) {
+	lineup := func() []Strategy {
+		return []Strategy{&StayAtK{20}, &StayAtK{22}, &StayAtK{15}, &StayAtK{30}}
+	}
+	first := RoundRobinSharedDice(lineup(), 100, 1)
+	if again := RoundRobinSharedDice(lineup(), 100, 1); !reflect.DeepEqual(again, first) {
+		t.Errorf("the same seed gave %+v, then %+v", first, again)
+	}
+	// How far apart StayAtK{20} and StayAtK{22} finish should vary less from
+	// seed to seed when they play the same dice.
+	var shared, plain []float64
+	for seed := int64(1); seed <= 30; seed++ {
+		r := RoundRobinSharedDice(lineup(), 200, seed)
+		shared = append(shared, r.Standings[0].winRate()-r.Standings[1].winRate())
+		r = Simulate(lineup(), 200, seed)
+		plain = append(plain, r.Standings[0].winRate()-r.Standings[1].winRate())
+	}
+	if s, p := meanStdDev(shared).StdDev, meanStdDev(plain).StdDev; s >= p {
+		t.Errorf("with shared dice the gap varies by %.2f points, want less than the %.2f without", s, p)
+	}
+}
+
+// capture is a slog.Handler that keeps every record.
+type capture struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (self *capture) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (self *capture) WithAttrs([]slog.Attr) slog.Handler {
+	return self
+}
+
+func (self *capture) WithGroup(string) slog.Handler {
+	return self
+}
+
+func (self *capture) Handle(_ context.Context, r slog.Record) error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.records = append(self.records, r)
+	return nil
+}
+
+// attrs returns the attributes of r by key.
+func attrs(r slog.Record) map[string]slog.Value {
+	m := make(map[string]slog.Value)
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value
+		return true
+	})
+	return m
+}
+
+func TestSimulateLogs(t *testing.T) {
+	h := &capture{}
+	lineup := []Strategy{&StayAtK{20}, &StayAtK{25}, &StayAtK{5}}
+	r := Simulate(lineup, 30, 7, WithLogger(slog.New(h)))
+	if len(h.records) != 5 {
+		t.Fatalf("got %d records, want a start, 3 series and a finish", len(h.records))
+	}
+	start, finish := h.records[0], h.records[len(h.records)-1]
+	if a := attrs(start); start.Message != "simulation started" || start.Level != slog.LevelInfo ||
+		a["strategies"].Int64() != 3 || a["seed"].Int64() != 7 || a["games"].Int64() != 30 {
+		t.Errorf("first record %q at %v with %v, want the start of 3 strategies, seed 7 and 30 games",
+			start.Message, start.Level, a)
+	}
+	for _, rec := range h.records[1 : len(h.records)-1] {
+		a := attrs(rec)
+		if rec.Message != "series finished" || rec.Level != slog.LevelDebug ||
+			a["winsA"].Int64()+a["winsB"].Int64() != 30 {
+			t.Errorf("record %q at %v with %v, want a finished series of 30 games", rec.Message, rec.Level, a)
+		}
+	}
+	if a := attrs(finish); finish.Message != "simulation finished" || a["games"].Int64() != int64(r.Games) ||
+		a["leader"].String() == "" {
+		t.Errorf("last record %q with %v, want the finish of %d games and its leader", finish.Message, a, r.Games)
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	before := SimResult{Standings: []Standing{
+		{&StayAtK{20}, 60, 40, 0}, // 60%, first
+		{&StayAtK{25}, 50, 50, 0}, // 50%, second
+		{&StayAtK{5}, 40, 60, 0},  // 40%, third, then dropped
+	}}
+	after := SimResult{Standings: []Standing{
+		{&StayAtK{25}, 70, 30, 0}, // 70%, second
+		{&StayAtK{20}, 55, 45, 0}, // 55%, third
+		{&Optimal{}, 75, 25, 0},   // 75%, new and first
+	}}
+	want := []StrategyDelta{
+		{Name: "Stay at 25", WinRate: 20, Rank: 0},
+		{Name: "Stay at 20", WinRate: -5, Rank: -2},
+		{Name: "Optimal", Added: true},
+		{Name: "Stay at 5", Removed: true},
+	}
+	got := DiffResults(before, after)
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		g := got[i]
+		if g.Name != want[i].Name || math.Abs(g.WinRate-want[i].WinRate) > 1e-9 || g.Rank != want[i].Rank ||
+			g.Added != want[i].Added || g.Removed != want[i].Removed {
+			t.Errorf("delta %d is %+v, want %+v", i, g, want[i])
+		}
+	}
+}
+
+func TestPayoffMatrix(t *testing.T) {
+	lineup := []Strategy{&StayAtK{20}, &StayAtK{5}, NewRandom(1), &StayAtK{30}}
+	m := PayoffMatrix(lineup, 300, 1)
+	r := Simulate(lineup, 300, 1)
+	for i := range m {
+		if m[i][i] != 0.5 {
+			t.Errorf("[%d][%d] is %g, want 0.5", i, i, m[i][i])
+		}
+		won := 0.0
+		for j := range m[i] {
+			if m[i][j] < 0 || m[i][j] > 1 {
+				t.Errorf("[%d][%d] is %g, not a win rate", i, j, m[i][j])
+			}
+			if j != i {
+				if sum := m[i][j] + m[j][i]; math.Abs(sum-1) > 1e-9 {
+					t.Errorf("[%d][%d] and [%d][%d] add up to %g, want 1", i, j, j, i, sum)
+				}
+				won += m[i][j] * 300
+			}
+		}
+		if int(math.Round(won)) != r.Standings[i].Wins {
+			t.Errorf("row %d adds up to %g wins, but Simulate gives %d", i, won, r.Standings[i].Wins)
+		}
+	}
+}
+
+func TestRoundRobinBudgetIsBalanced(t *testing.T) {
+	// 1003 games between 10 pairs: three series play 101 games and seven
+	// play 100, so each strategy's 4 series add up to 400 to 403 games.
+	lineup := []Strategy{&StayAtK{15}, &StayAtK{20}, &StayAtK{25}, &StayAtK{30}, NewRandom(1)}
+	r := RoundRobinBudget(lineup, 1003, 1)
+	total, extra := 0, 0
+	for _, st := range r.Standings {
+		played := st.Wins + st.Losses + st.Draws
+		if played < 400 || played > 403 {
+			t.Errorf("%v played %d games, want 400 to 403", st.Strategy, played)
+		}
+		total += played
+		extra += played - 400
+	}
+	if total != 2*1003 || extra != 2*3 {
+		t.Errorf("the standings count %d players' games, %d over 100 a series, want %d and 6", total, extra, 2*1003)
+	}
+}
+
+// drifting stays at a threshold that moves with every decision it makes and
+// is never reset, so how it plays a series depends on what it played before.
+type drifting struct {
+	decisions int
+}
+
+func (self *drifting) nextAction(s score) action {
+	self.decisions++
+	if s.thisTurn >= 10+self.decisions%30 {
+		return stay
+	}
+	return roll
+}
+
+func (self *drifting) String() string {
+	return "drifting"
+}
+
+func (self *drifting) Clone() Strategy {
+	c := *self
+	return &c
+}
+
+func TestAuditOrderIndependence(t *testing.T) {
+	lineup := []Strategy{&StayAtK{20}, &StayAtK{25}, &Random{}, &Optimal{}}
+	if !AuditOrderIndependence(lineup, 200, 1) {
+		t.Error("the seeded round robin depends on the order of its lineup")
+	}
+	if AuditOrderIndependence([]Strategy{&drifting{}, &StayAtK{20}, &StayAtK{25}}, 200, 1) {
+		t.Error("a strategy whose play depends on the series it played before passed the audit")
+	}
+}
+
+func TestDominatedStrategies(t *testing.T) {
+	// 0 beats 1 and does better than it against everyone else; 2 and 3 each
+	// beat someone the other loses to.
+	matrix := [][]int{
+		{0, 70, 40, 60},
+		{30, 0, 30, 50},
+		{60, 70, 0, 20},
+		{40, 50, 80, 0},
+	}
+	if got := DominatedStrategies(matrix, 100); !slices.Equal(got, []int{1}) {
+		t.Errorf("the dominated strategies are %v, want [1]", got)
+	}
+	lineup := []Strategy{&StayAtK{20}, &Random{}, &StayAtK{25}}
+	if got := DominatedStrategies(HeadToHead(lineup, 500, 1), 500); !slices.Equal(got, []int{1}) {
+		t.Errorf("the dominated strategies of %v are %v, want just Random", lineup, got)
+	}
+}
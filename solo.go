@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// The most turns PlaySolo plays before giving up, so that a strategy that
+// never banks anything can't play forever.
+const maxSoloTurns = 10000
+
+// PlaySolo plays Pig alone, as if against an opponent who never scores,
+// rolling with rng, and returns how many turns strategy took to win, or
+// maxSoloTurns if it hadn't won by then.
+func PlaySolo(strategy Strategy, rng *rand.Rand) int {
+	d := GameConfig{}.die(rng)
+	var s score
+	turns := 1
+	for s.player+s.thisTurn < win && turns < maxSoloTurns {
+		next, turnIsOver := actionAt(strategy, GameState{s, turns})(s, d)
+		if !turnIsOver {
+			s = next
+			continue
+		}
+		// The roles have swapped, so the player's banked score is now the
+		// opponent's.
+		s = score{next.opponent, 0, 0}
+		turns++
+	}
+	return turns
+}
+
+// A SoloStat summarizes how many turns a strategy takes to win alone.
+type SoloStat struct {
+	Strategy   Strategy
+	TurnsToWin RepeatedStat
+}
+
+// SoloBenchmark plays each of strategies alone runs times, the r-th run
+// seeded with baseSeed+r, and returns the mean and standard deviation of the
+// turns each took to win. The strategies are benchmarked concurrently, each
+// by its own clone, reseeded for each run if it is a Seeder, so the results
+// only depend on the strategies and baseSeed.
+func SoloBenchmark(strategies []Strategy, runs int, baseSeed int64) []SoloStat {
+	stats := make([]SoloStat, len(strategies))
+	var wg sync.WaitGroup
+	for i, strategy := range strategies {
+		wg.Add(1)
+		go func(i int, player Strategy) {
+			defer wg.Done()
+			turns := make([]float64, runs)
+			for r := range turns {
+				rng := rand.New(rand.NewSource(baseSeed + int64(r)))
+				if seeder, ok := player.(Seeder); ok {
+					seeder.Seed(rng.Int63())
+				}
+				reset(player)
+				turns[r] = float64(PlaySolo(player, rng))
+			}
+			stats[i] = SoloStat{strategies[i], meanStdDev(turns)}
+		}(i, clone(strategy))
+	}
+	wg.Wait()
+	return stats
+}
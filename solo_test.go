@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestSoloBenchmarkFavorsAggression(t *testing.T) {
+	lineup := []Strategy{&StayAtK{25}, &StayAtK{5}, &Random{}}
+	stats := SoloBenchmark(lineup, 500, 1)
+	aggressive, conservative := stats[0].TurnsToWin, stats[1].TurnsToWin
+	if aggressive.Mean >= conservative.Mean {
+		t.Errorf("StayAtK{25} took %.1f turns to win on average and StayAtK{5} %.1f, want the aggressive one faster",
+			aggressive.Mean, conservative.Mean)
+	}
+	for i, st := range SoloBenchmark(lineup, 500, 1) {
+		if st != stats[i] {
+			t.Errorf("benchmarking %v again gave %+v, want %+v", lineup[i], st.TurnsToWin, stats[i].TurnsToWin)
+		}
+	}
+	if other := SoloBenchmark(lineup, 500, 2); other[0] == stats[0] {
+		t.Errorf("benchmarks seeded with 1 and 2 both gave %+v", other[0].TurnsToWin)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A GameState is everything the engine tells a strategy about a game in
+// progress: the score, and how many turns the current player has started,
+// counting the current one from 1. The engine passes strategies a copy, so
+// nothing a strategy does to it can change the game; for that to hold,
+// GameState must not gain pointer, slice or map fields.
+type GameState struct {
+	score
+	Turn int
+}
+
+// A StateStrategy is a Strategy that decides from the whole GameState rather
+// than just the score.
+type StateStrategy interface {
+	Strategy
+	NextActionAt(g GameState) action
+}
+
+// actionAt returns strategy's action in g.
+func actionAt(strategy Strategy, g GameState) action {
+	if ss, ok := strategy.(StateStrategy); ok {
+		return ss.NextActionAt(g)
+	}
+	return strategy.nextAction(g.score)
+}
+
+// Alternating plays a on its odd-numbered turns and b on its even-numbered
+// turns.
+type Alternating struct {
+	a, b Strategy
+}
+
+func (self *Alternating) NextActionAt(g GameState) action {
+	if g.Turn%2 == 1 {
+		return actionAt(self.a, g)
+	}
+	return actionAt(self.b, g)
+}
+
+// nextAction plays a, since the turn is unknown.
+func (self *Alternating) nextAction(s score) action {
+	return self.a.nextAction(s)
+}
+
+func (self *Alternating) String() string {
+	return fmt.Sprintf("Alt(%v,%v)", self.a, self.b)
+}
+
+// OpeningBook follows a scripted plan for its first bookTurns turns, staying
+// on turn t once thisTurn reaches plan[t-1], and plays fallback after that.
+type OpeningBook struct {
+	bookTurns int
+	plan      []int
+	fallback  Strategy
+}
+
+// NewOpeningBook returns an OpeningBook that follows plan for len(plan)
+// turns and then plays fallback.
+func NewOpeningBook(plan []int, fallback Strategy) *OpeningBook {
+	return &OpeningBook{len(plan), plan, fallback}
+}
+
+func (self *OpeningBook) NextActionAt(g GameState) action {
+	if g.Turn < 1 || g.Turn > self.bookTurns {
+		return actionAt(self.fallback, g)
+	}
+	if g.thisTurn >= self.plan[g.Turn-1] {
+		return stay
+	}
+	return roll
+}
+
+// nextAction plays fallback, since the turn is unknown.
+func (self *OpeningBook) nextAction(s score) action {
+	return self.fallback.nextAction(s)
+}
+
+func (self *OpeningBook) String() string {
+	plan := make([]string, len(self.plan))
+	for i, k := range self.plan {
+		plan[i] = fmt.Sprint(k)
+	}
+	return fmt.Sprintf("Book(%s then %v)", strings.Join(plan, ","), self.fallback)
+}
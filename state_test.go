@@ -0,0 +1,80 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlternatingDelegatesByTurn(t *testing.T) {
+	alt := &Alternating{&StayAtK{5}, &StayAtK{30}}
+	s := score{0, 0, 10}
+	for _, test := range []struct {
+		turn int
+		roll bool
+	}{{1, false}, {2, true}, {3, false}, {4, true}} {
+		if got := isRoll(alt.NextActionAt(GameState{s, test.turn})); got != test.roll {
+			t.Errorf("turn %d: rolled=%v at %+v, want %v", test.turn, got, s, test.roll)
+		}
+	}
+}
+
+func TestOpeningBookThenFallback(t *testing.T) {
+	fallback := &CountingStrategy{strategy: &StayAtK{20}}
+	book := NewOpeningBook([]int{5, 30}, fallback)
+	for _, test := range []struct {
+		turn, thisTurn int
+		roll           bool
+	}{{1, 3, true}, {1, 10, false}, {2, 25, true}, {2, 30, false}} {
+		g := GameState{score{0, 0, test.thisTurn}, test.turn}
+		if isRoll(book.NextActionAt(g)) != test.roll {
+			t.Errorf("book turn %d at %d: rolled=%v, want %v", test.turn, test.thisTurn, !test.roll, test.roll)
+		}
+	}
+	if fallback.Rolls+fallback.Stays != 0 {
+		t.Errorf("the fallback was asked %d times during the book, want 0", fallback.Rolls+fallback.Stays)
+	}
+	if isRoll(book.NextActionAt(GameState{score{0, 0, 25}, 3})) {
+		t.Error("after the book, rolled at 25, want the fallback to stay")
+	}
+	if !isRoll(book.NextActionAt(GameState{score{0, 0, 10}, 4})) {
+		t.Error("after the book, stayed at 10, want the fallback to roll")
+	}
+	if fallback.Rolls != 1 || fallback.Stays != 1 {
+		t.Errorf("the fallback rolled %d and stayed %d times after the book, want 1 and 1",
+			fallback.Rolls, fallback.Stays)
+	}
+}
+
+// tamperer plays like StayAtK{20}, but scribbles over the state it's given
+// first.
+type tamperer struct{}
+
+func (self *tamperer) NextActionAt(g GameState) action {
+	a := (&StayAtK{20}).nextAction(g.score)
+	g.player, g.opponent, g.thisTurn, g.Turn = 99, 0, 50, -1
+	return a
+}
+
+func (self *tamperer) nextAction(s score) action {
+	return self.NextActionAt(GameState{s, 1})
+}
+
+func (self *tamperer) String() string {
+	return "tamperer"
+}
+
+func TestTamperingWithGameStateChangesNothing(t *testing.T) {
+	for first := 0; first < 2; first++ {
+		want := PlayDetailed(&StayAtK{20}, &StayAtK{25}, first, 3)
+		if got := PlayDetailed(&tamperer{}, &StayAtK{25}, first, 3); got != want {
+			t.Errorf("with player %d first, tampering changed the game from %+v to %+v", first, want, got)
+		}
+	}
+	fields := reflect.TypeOf(GameState{})
+	for i := 0; i < fields.NumField(); i++ {
+		switch fields.Field(i).Type.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			t.Errorf("GameState.%s can be shared with the engine", fields.Field(i).Name)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"time"
+)
+
+// isRoll reports whether a rolls the die. Actions are pure functions of the
+// score and the dice, so a can be tried on an empty score to find out.
+func isRoll(a action) bool {
+	rolled := false
+	a(score{}, func() int {
+		rolled = true
+		return 2
+	})
+	return rolled
+}
+
+// CountingStrategy plays like strategy while tallying how often it chooses to
+// roll, to stay and, under the BankHalf variant, to bank half its turn
+// total. It is not safe for concurrent use.
+type CountingStrategy struct {
+	strategy                Strategy
+	Rolls, Stays, HalfBanks int
+}
+
+func (self *CountingStrategy) count(a action) action {
+	switch {
+	case isRoll(a):
+		self.Rolls++
+	case isStay(a):
+		self.Stays++
+	default:
+		self.HalfBanks++
+	}
+	return a
+}
+
+// isStay reports whether a ends the turn without rolling, as stay does and
+// bankHalf doesn't.
+func isStay(a action) bool {
+	_, turnIsOver := a(score{0, 0, 2}, func() int {
+		return 2
+	})
+	return turnIsOver && !isRoll(a)
+}
+
+func (self *CountingStrategy) NextActionAt(g GameState) action {
+	return self.count(actionAt(self.strategy, g))
+}
+
+func (self *CountingStrategy) nextAction(s score) action {
+	return self.count(self.strategy.nextAction(s))
+}
+
+func (self *CountingStrategy) String() string {
+	return fmt.Sprintf("%v (counted)", self.strategy)
+}
+
+// TimedStrategy plays like strategy while adding up how long strategy takes
+// to decide. It is not safe for concurrent use.
+type TimedStrategy struct {
+	strategy  Strategy
+	Elapsed   time.Duration
+	Decisions int
+}
+
+func (self *TimedStrategy) NextActionAt(g GameState) action {
+	start := time.Now()
+	a := actionAt(self.strategy, g)
+	self.Elapsed += time.Since(start)
+	self.Decisions++
+	return a
+}
+
+func (self *TimedStrategy) nextAction(s score) action {
+	return self.NextActionAt(GameState{score: s})
+}
+
+func (self *TimedStrategy) String() string {
+	return fmt.Sprintf("%v (timed)", self.strategy)
+}
+
+// DecisionTimes plays the same series as Simulate, one at a time so that
+// the strategies don't compete for CPUs, and returns the total time each of
+// strategies took to decide.
+func DecisionTimes(strategies []Strategy, games int, seed int64) []time.Duration {
+	players := cloneAll(strategies)
+	timed := make([]*TimedStrategy, len(players))
+	for i, player := range players {
+		timed[i] = &TimedStrategy{strategy: player}
+	}
+	for i := range timed {
+		for j := i + 1; j < len(timed); j++ {
+			a, b, series := pairSeries(seed, players, i, j)
+			rng := reseed(players[a], players[b], series)
+			CompareStrategies(timed[a], timed[b], games, rng.Int63())
+		}
+	}
+	times := make([]time.Duration, len(timed))
+	for i, t := range timed {
+		times[i] = t.Elapsed
+	}
+	return times
+}
+
+// visitingStrategy plays like strategy while counting the scores at which
+// it decides in visits, by Key.
+type visitingStrategy struct {
+	strategy Strategy
+	visits   map[uint32]int
+}
+
+func (self *visitingStrategy) NextActionAt(g GameState) action {
+	self.visits[g.score.Key()]++
+	return actionAt(self.strategy, g)
+}
+
+func (self *visitingStrategy) nextAction(s score) action {
+	return self.NextActionAt(GameState{score: s})
+}
+
+func (self *visitingStrategy) String() string {
+	return self.strategy.String()
+}
+
+// VisitedStates plays the same series as PlaySeries and returns how many
+// times either player had to decide at each score, keyed by the score's
+// Key and from the point of view of the player deciding. The counts add up
+// to the number of decisions made.
+func VisitedStates(s0, s1 Strategy, games int, seed int64) map[uint32]int {
+	visits := make(map[uint32]int)
+	PlaySeries(&visitingStrategy{s0, visits}, &visitingStrategy{s1, visits}, games, seed)
+	return visits
+}
+
+// ActionStats plays strategy against a copy of itself for games seeded games
+// and returns how many times it chose to roll and to stay.
+func ActionStats(strategy Strategy, games int, seed int64) (rolls, stays int) {
+	counter := &CountingStrategy{strategy: strategy}
+	PlaySeries(counter, clone(strategy), games, seed)
+	return counter.Rolls, counter.Stays
+}
+
+// BustStats plays the same games as ActionStats and returns how many of
+// strategy's turns ended in a bust, rolling a 1, and how many it banked,
+// counting the turns on which it won.
+func BustStats(strategy Strategy, games int, seed int64) (busts, banks int) {
+	other := clone(strategy)
+	reset(strategy, other)
+	dice := GameConfig{}.die(rand.New(rand.NewSource(seed)))
+	for i := 0; i < games; i++ {
+		winner := playGame(GameConfig{}, strategy, other, i%2, dice, func(t Turn) {
+			switch {
+			case t.Player != 0 || !t.Ended:
+			case t.Die != 0:
+				busts++
+			default:
+				banks++
+			}
+		})
+		if winner == 0 {
+			banks++
+		}
+	}
+	return busts, banks
+}
+
+// FirstMoverAdvantage plays strategy against a copy of itself for games
+// seeded games, with the original always moving first, and returns the
+// fraction of them it won less one half. Since both players play alike,
+// that is how much moving first is worth to the strategy.
+func FirstMoverAdvantage(strategy Strategy, games int, seed int64) float64 {
+	other := clone(strategy)
+	reset(strategy, other)
+	dice := GameConfig{}.die(rand.New(rand.NewSource(seed)))
+	won := 0
+	for i := 0; i < games; i++ {
+		if playGame(GameConfig{}, strategy, other, 0, dice, nil) == 0 {
+			won++
+		}
+	}
+	return float64(won)/float64(games) - 0.5
+}
+
+// Exploitability returns how far above one half the best of a field of
+// counter-strategies wins against strategy, over games seeded games each.
+// The field is StayAtK for every k up to win/2, Optimal, SmartEV and
+// LeadTarget. Since it takes the best of many noisy win rates, it tends to
+// overstate the advantage a little when games is small.
+func Exploitability(strategy Strategy, games int, seed int64) float64 {
+	var counters []Strategy
+	for k := 1; k <= win/2; k++ {
+		counters = append(counters, &StayAtK{k})
+	}
+	counters = append(counters, &Optimal{}, &SmartEV{20}, &LeadTarget{20})
+	best := 0.0
+	for _, counter := range counters {
+		target := clone(strategy)
+		reset(counter, target)
+		best = max(best, CompareStrategies(counter, target, games, seed).winRateA())
+	}
+	return best - 0.5
+}
+
+// RobustnessProfile returns strategy's win rate against opponent over games
+// seeded games in a game to each of winScores, to show how well it plays
+// away from the winning score it was designed for. Scores that aren't
+// valid winning scores are left out.
+func RobustnessProfile(strategy Strategy, opponent Strategy, winScores []int, games int, seed int64) map[int]float64 {
+	profile := make(map[int]float64, len(winScores))
+	for _, w := range winScores {
+		if w <= 0 {
+			continue
+		}
+		a, b := clone(strategy), clone(opponent)
+		reset(a, b)
+		c, err := GameConfig{Win: w}.CompareStrategies(a, b, games, seed)
+		if err == nil {
+			profile[w] = c.winRateA()
+		}
+	}
+	return profile
+}
+
+// A ProfileResult reports what a strategy costs to run.
+type ProfileResult struct {
+	AllocsPerGame    float64
+	DecisionsPerGame float64
+}
+
+// ProfileStrategy plays s against StayAtK{20} for games games and reports
+// the memory allocations made by s and the decisions it took, per game.
+func ProfileStrategy(s Strategy, games int) ProfileResult {
+	opponent := &StayAtK{20}
+	decisions := 0
+	count := func(t Turn) {
+		if t.Player == 0 {
+			decisions++
+		}
+	}
+	run := func(player Strategy) uint64 {
+		dice := GameConfig{}.die(rand.New(rand.NewSource(1)))
+		return allocations(func() {
+			for i := 0; i < games; i++ {
+				playGame(GameConfig{}, player, opponent, i%2, dice, count)
+			}
+		})
+	}
+	// The opponent allocates nothing, so playing it against itself measures
+	// the engine's own allocations.
+	baseline := run(opponent)
+	decisions = 0
+	allocs := float64(run(s)) - float64(baseline)
+	if allocs < 0 {
+		allocs = 0
+	}
+	return ProfileResult{allocs / float64(games), float64(decisions) / float64(games)}
+}
+
+// allocations returns the number of heap allocations made while running f.
+func allocations(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.Mallocs - before.Mallocs
+}
+
+// IsDeterministic reports whether s makes the same decisions when asked
+// twice about the same trials game states, chosen at random from seed. Each
+// time, s is asked through a fresh clone, reseeded with seed if it is a
+// Seeder, so strategies that only draw from their own source pass.
+func IsDeterministic(s Strategy, trials int, seed int64) bool {
+	rng := rand.New(rand.NewSource(seed))
+	states := make([]GameState, trials)
+	for i := range states {
+		player := rng.Intn(win)
+		states[i] = GameState{
+			score{player, rng.Intn(win), rng.Intn(win - player)},
+			rng.Intn(20) + 1,
+		}
+	}
+	type decision struct {
+		result     score
+		turnIsOver bool
+	}
+	decide := func() []decision {
+		c := clone(s)
+		if seeder, ok := c.(Seeder); ok {
+			seeder.Seed(seed)
+		}
+		decisions := make([]decision, len(states))
+		for i, g := range states {
+			// Replay every action with the same dice, so that any two
+			// different actions are told apart by their results.
+			outcome := 1
+			result, turnIsOver := actionAt(c, g)(g.score, func() int {
+				outcome = outcome%6 + 1
+				return outcome
+			})
+			decisions[i] = decision{result, turnIsOver}
+		}
+		return decisions
+	}
+	first, second := decide(), decide()
+	for i := range first {
+		if first[i] != second[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// The number of game states ValidateStrategy asks about.
+const validationStates = 1000
+
+// ValidateStrategy checks that s is fit to play: that its name isn't empty,
+// that it chooses an action in each of validationStates game states chosen
+// at random, and that it stays in at least one of them with a turn total
+// to bank. A strategy that never stays can only win by reaching win in a
+// single turn, so games between such strategies may never end. s is asked
+// through a clone, so it is left as it was.
+func ValidateStrategy(s Strategy) error {
+	if s.String() == "" {
+		return fmt.Errorf("strategy %T has no name", s)
+	}
+	c := clone(s)
+	rng := rand.New(rand.NewSource(1))
+	stays := false
+	for i := 0; i < validationStates; i++ {
+		player := rng.Intn(win)
+		g := GameState{score{player, rng.Intn(win), rng.Intn(win - player)}, rng.Intn(20) + 1}
+		a := actionAt(c, g)
+		if a == nil {
+			return fmt.Errorf("strategy %v chose no action at %+v", s, g)
+		}
+		stays = stays || (g.thisTurn > 0 && !isRoll(a))
+	}
+	if !stays {
+		return fmt.Errorf("strategy %v never stayed in %d states", s, validationStates)
+	}
+	return nil
+}
+
+// WinningScoreHistogram plays the same games as PlaySeries and counts how
+// often the winner finished on each final score.
+func WinningScoreHistogram(a, b Strategy, games int, seed int64) map[int]int {
+	dice := GameConfig{}.die(rand.New(rand.NewSource(seed)))
+	histogram := make(map[int]int)
+	for i := 0; i < games; i++ {
+		d := playDetailed(GameConfig{}, a, b, i%2, dice, nil)
+		histogram[d.Scores[d.Winner]]++
+	}
+	return histogram
+}
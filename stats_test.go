@@ -0,0 +1,233 @@
+package main
+
+import (
+	"math"
+	"math/bits"
+	"testing"
+	"time"
+)
+
+func TestActionStatsRollStayRatio(t *testing.T) {
+	ratio := func(k int) float64 {
+		rolls, stays := ActionStats(&StayAtK{k}, 500, 1)
+		return float64(rolls) / float64(stays)
+	}
+	if low, high := ratio(5), ratio(30); high < 3*low {
+		t.Errorf("StayAtK{30} rolls %.2f times per stay and StayAtK{5} %.2f, want a much higher ratio for 30", high, low)
+	}
+}
+
+func TestCountingStrategyCountsHalfBanks(t *testing.T) {
+	counter := &CountingStrategy{strategy: &BankHalfAtK{10}}
+	GameConfig{BankHalf: true, MaxTurns: 50}.CompareStrategies(counter, &StayAtK{20}, 20, 1)
+	if counter.HalfBanks == 0 || counter.Stays != 0 {
+		t.Errorf("BankHalfAtK counted %d half banks and %d stays, want some half banks and no stays",
+			counter.HalfBanks, counter.Stays)
+	}
+}
+
+// allocating plays like StayAtK{20}, but allocates on every decision.
+type allocating struct {
+	kept [][]int
+}
+
+func (self *allocating) nextAction(s score) action {
+	self.kept = append(self.kept, make([]int, 8))
+	return (&StayAtK{20}).nextAction(s)
+}
+
+func (self *allocating) String() string {
+	return "allocating"
+}
+
+func TestProfileStrategyCountsAllocations(t *testing.T) {
+	lean := ProfileStrategy(&StayAtK{20}, 200)
+	if lean.AllocsPerGame > 1 {
+		t.Errorf("StayAtK made %.2f allocations per game, want about 0", lean.AllocsPerGame)
+	}
+	if lean.DecisionsPerGame == 0 {
+		t.Error("StayAtK made no decisions")
+	}
+	heavy := ProfileStrategy(&allocating{}, 200)
+	if heavy.AllocsPerGame < heavy.DecisionsPerGame/2 {
+		t.Errorf("a strategy allocating on each of %.1f decisions per game made %.2f allocations per game",
+			heavy.DecisionsPerGame, heavy.AllocsPerGame)
+	}
+}
+
+// clockReader rolls or stays on the parity of the bits of the wall clock's
+// time, which changes with every tick whatever the clock's resolution.
+type clockReader struct{}
+
+func (self *clockReader) nextAction(s score) action {
+	if bits.OnesCount64(uint64(time.Now().UnixNano()))%2 == 0 {
+		return roll
+	}
+	return stay
+}
+
+func (self *clockReader) String() string {
+	return "clock"
+}
+
+func TestIsDeterministic(t *testing.T) {
+	if !IsDeterministic(&StayAtK{20}, 200, 1) {
+		t.Error("StayAtK reported as nondeterministic")
+	}
+	if !IsDeterministic(NewRandom(5), 200, 1) {
+		t.Error("Random, reseeded each time, reported as nondeterministic")
+	}
+	if IsDeterministic(&clockReader{}, 1000, 1) {
+		t.Error("a strategy reading the clock reported as deterministic")
+	}
+}
+
+func TestWinningScoreHistogram(t *testing.T) {
+	const games = 1000
+	histogram := WinningScoreHistogram(&StayAtK{20}, &StayAtK{25}, games, 1)
+	total := 0
+	for score, count := range histogram {
+		if score < win || score >= win+25 {
+			t.Errorf("%d games were won on %d, want scores from %d to %d", count, score, win, win+24)
+		}
+		total += count
+	}
+	if total != games {
+		t.Errorf("the histogram counts %d games, want %d", total, games)
+	}
+}
+
+func TestFirstMoverAdvantage(t *testing.T) {
+	if adv := FirstMoverAdvantage(&StayAtK{20}, 20000, 1); adv < 0.02 {
+		t.Errorf("moving first is worth %.4f to StayAtK{20}, want a clear advantage", adv)
+	}
+	// Rolling once in twenty decisions, a game takes so many turns that
+	// moving first hardly matters.
+	barely, err := NewWeightedRandom(0.05, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adv := FirstMoverAdvantage(barely, 20000, 1); math.Abs(adv) > 0.015 {
+		t.Errorf("moving first is worth %.4f to a strategy that barely scores, want about 0", adv)
+	}
+}
+
+func TestExploitability(t *testing.T) {
+	optimal := Exploitability(&Optimal{}, 1000, 1)
+	naive := Exploitability(&StayAtK{5}, 1000, 1)
+	if optimal > 0.08 {
+		t.Errorf("Optimal's exploitability is %.3f, want it low", optimal)
+	}
+	if naive < optimal+0.15 {
+		t.Errorf("StayAtK{5}'s exploitability is %.3f, want it well above Optimal's %.3f", naive, optimal)
+	}
+}
+
+func TestDecisionTimesAndDeadlines(t *testing.T) {
+	times := DecisionTimes([]Strategy{&StayAtK{20}, &slow{200 * time.Microsecond}}, 3, 1)
+	if times[1] <= times[0] || times[1] < 10*200*time.Microsecond {
+		t.Errorf("the slow strategy took %v and StayAtK %v, want the slow one to take far longer", times[1], times[0])
+	}
+
+	cfg := GameConfig{DecisionDeadline: time.Millisecond}
+	c, err := cfg.CompareStrategies(&slow{5 * time.Millisecond}, &StayAtK{20}, 4, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.WinsB != 4 {
+		t.Errorf("a strategy over the deadline forfeited %d of 4 games, want it to forfeit them all", c.WinsB)
+	}
+	cfg.DecisionDeadline = time.Minute
+	c, _ = cfg.CompareStrategies(&StayAtK{25}, &StayAtK{20}, 200, 1)
+	if want := CompareStrategies(&StayAtK{25}, &StayAtK{20}, 200, 1); c != want {
+		t.Errorf("well within the deadline the series went %+v, want %+v as without one", c, want)
+	}
+}
+
+func TestRobustnessProfileAcrossWinningScores(t *testing.T) {
+	for _, opponent := range []Strategy{&StayAtK{10}, &Random{}} {
+		profile := RobustnessProfile(&StayAtK{20}, opponent, []int{10, 100, 0, -5}, 4000, 1)
+		if len(profile) != 2 {
+			t.Errorf("the profile against %v is %v, want just the valid winning scores 10 and 100", opponent, profile)
+		}
+		if profile[10] >= profile[100] {
+			t.Errorf("against %v StayAtK{20} won %.3f of its games to 10 and %.3f to 100, want it worse to 10",
+				opponent, profile[10], profile[100])
+		}
+	}
+}
+
+func TestVisitedStatesCountsEveryDecision(t *testing.T) {
+	a, b := &CountingStrategy{strategy: &StayAtK{20}}, &CountingStrategy{strategy: &StayAtK{25}}
+	visits := VisitedStates(a, b, 500, 1)
+	total := 0
+	for _, n := range visits {
+		total += n
+	}
+	if decisions := a.Rolls + a.Stays + b.Rolls + b.Stays; total != decisions {
+		t.Errorf("the visits add up to %d, but %d decisions were made", total, decisions)
+	}
+	start := visits[score{}.Key()]
+	if start < 500 {
+		t.Errorf("the start was visited %d times in 500 games, want at least once a game", start)
+	}
+	for key, n := range visits {
+		if n > start {
+			t.Errorf("state %#x was visited %d times, more than the start's %d", key, n, start)
+		}
+	}
+	if (score{1, 2, 3}).Key() == (score{3, 2, 1}).Key() {
+		t.Error("scores 1, 2, 3 and 3, 2, 1 have the same key")
+	}
+}
+
+// nameless is StayAtK without a name.
+type nameless struct {
+	StayAtK
+}
+
+func (self *nameless) String() string {
+	return ""
+}
+
+func TestValidateStrategy(t *testing.T) {
+	strategies := []Strategy{
+		NewLogisticStrategy([4]float64{19.5, 0, 0, -1}),
+		NewTableStrategy([]int{10, 30}, 20),
+		NewMonteCarlo(10, &StayAtK{20}, nil, 1),
+		&RiskAverse{0.05},
+		&BankHalfAtK{10},
+	}
+	for name := range registry {
+		s, err := buildStrategy(name, nil)
+		if err != nil {
+			t.Fatalf("building %q: %v", name, err)
+		}
+		strategies = append(strategies, s)
+	}
+	for _, s := range strategies {
+		if err := ValidateStrategy(s); err != nil {
+			t.Errorf("validating %v: %v", s, err)
+		}
+	}
+	// Without a delay, slow just always rolls.
+	if err := ValidateStrategy(&slow{0}); err == nil {
+		t.Error("a strategy that always rolls passed validation")
+	}
+	if err := ValidateStrategy(&nameless{StayAtK{20}}); err == nil {
+		t.Error("a strategy without a name passed validation")
+	}
+}
+
+func TestBustStatsRiseWithK(t *testing.T) {
+	bustRate := func(k int) float64 {
+		busts, banks := BustStats(&StayAtK{k}, 500, 1)
+		if busts == 0 || banks < 500/2 {
+			t.Errorf("StayAtK{%d} busted %d turns and banked %d in 500 games", k, busts, banks)
+		}
+		return float64(busts) / float64(busts+banks)
+	}
+	if timid, bold := bustRate(5), bustRate(30); bold <= timid {
+		t.Errorf("StayAtK{30} busted %.3f of its turns and StayAtK{5} %.3f, want StayAtK{30} to bust more", bold, timid)
+	}
+}
@@ -0,0 +1,469 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Adaptive is like StayAtK, but picks k by whether its banked score is
+// behind, level with or ahead of its opponent's.
+type Adaptive struct {
+	behind, even, ahead int
+}
+
+func (self *Adaptive) nextAction(s score) action {
+	k := self.even
+	if s.player < s.opponent {
+		k = self.behind
+	} else if s.player > s.opponent {
+		k = self.ahead
+	}
+	if s.thisTurn >= k {
+		return stay
+	}
+	return roll
+}
+
+func (self *Adaptive) String() string {
+	return fmt.Sprintf("Adaptive(behind=%d,even=%d,ahead=%d)", self.behind, self.even, self.ahead)
+}
+
+// CappedStayAtK is like StayAtK, but also stays as soon as banking thisTurn
+// would reach target, since more points could not help. The engine ends a
+// standard game once the winning score is reached, so the cap only makes a
+// difference to rules and callers that ask for a decision beyond that point.
+type CappedStayAtK struct {
+	k, target int
+}
+
+func (self *CappedStayAtK) nextAction(s score) action {
+	if s.thisTurn >= self.k || s.thisTurn >= self.target-s.player {
+		return stay
+	}
+	return roll
+}
+
+func (self *CappedStayAtK) String() string {
+	return fmt.Sprintf("Stay at %d capped at %d", self.k, self.target)
+}
+
+// LeadTarget rolls until banking thisTurn would put it leadGoal points ahead
+// of its opponent, then stays.
+type LeadTarget struct {
+	leadGoal int
+}
+
+func (self *LeadTarget) nextAction(s score) action {
+	if s.player+s.thisTurn-s.opponent >= self.leadGoal {
+		return stay
+	}
+	return roll
+}
+
+func (self *LeadTarget) String() string {
+	return fmt.Sprintf("Lead target %d", self.leadGoal)
+}
+
+// Mix plays, at each decision, the action of one of its members chosen at
+// random in proportion to its weight. Like Random, it is not safe for
+// concurrent use; concurrent series use clones.
+type Mix struct {
+	members []Strategy
+	weights []float64
+	total   float64
+	rng     *rand.Rand
+}
+
+// NewMix returns a Mix of members with the given weights, which must be
+// positive, drawing from its own source seeded with seed.
+func NewMix(members []Strategy, weights []float64, seed int64) (*Mix, error) {
+	if len(members) == 0 || len(members) != len(weights) {
+		return nil, fmt.Errorf("mix needs one weight per member, got %d members and %d weights",
+			len(members), len(weights))
+	}
+	m := &Mix{members: members, weights: weights, rng: rand.New(rand.NewSource(seed))}
+	for _, w := range weights {
+		if w <= 0 {
+			return nil, fmt.Errorf("mix weight %g is not positive", w)
+		}
+		m.total += w
+	}
+	return m, nil
+}
+
+// member returns a member chosen at random in proportion to its weight.
+func (self *Mix) member() Strategy {
+	x := self.rng.Float64() * self.total
+	for i, w := range self.weights {
+		if x < w {
+			return self.members[i]
+		}
+		x -= w
+	}
+	return self.members[len(self.members)-1]
+}
+
+func (self *Mix) NextActionAt(g GameState) action {
+	return actionAt(self.member(), g)
+}
+
+func (self *Mix) nextAction(s score) action {
+	return self.member().nextAction(s)
+}
+
+// Seed reseeds self's source, and that of each member that is a Seeder,
+// from seed.
+func (self *Mix) Seed(seed int64) {
+	self.rng.Seed(seed)
+	for _, member := range self.members {
+		if seeder, ok := member.(Seeder); ok {
+			seeder.Seed(self.rng.Int63())
+		}
+	}
+}
+
+// Clone returns a Mix of clones of self's members, with its own source
+// seeded from self's.
+func (self *Mix) Clone() Strategy {
+	m, _ := NewMix(cloneAll(self.members), self.weights, self.rng.Int63())
+	return m
+}
+
+func (self *Mix) String() string {
+	parts := make([]string, len(self.members))
+	for i, member := range self.members {
+		parts[i] = fmt.Sprintf("%g %v", self.weights[i], member)
+	}
+	return "Mix(" + strings.Join(parts, ", ") + ")"
+}
+
+// TimeBudget plays like strategy, but stays if strategy takes longer than
+// budget to decide. A late decision is still allowed to finish in the
+// background and is then discarded, so strategy may be asked for its next
+// decision before its last one is done.
+type TimeBudget struct {
+	strategy Strategy
+	budget   time.Duration
+}
+
+// within returns the action decide returns, or stay if it takes longer than
+// the budget.
+func (self *TimeBudget) within(decide func() action) action {
+	// Buffered, so that a late decision can be sent and its goroutine exit.
+	decision := make(chan action, 1)
+	go func() {
+		decision <- decide()
+	}()
+	timer := time.NewTimer(self.budget)
+	defer timer.Stop()
+	select {
+	case a := <-decision:
+		return a
+	case <-timer.C:
+		return stay
+	}
+}
+
+func (self *TimeBudget) NextActionAt(g GameState) action {
+	return self.within(func() action {
+		return actionAt(self.strategy, g)
+	})
+}
+
+func (self *TimeBudget) nextAction(s score) action {
+	return self.within(func() action {
+		return self.strategy.nextAction(s)
+	})
+}
+
+func (self *TimeBudget) String() string {
+	return fmt.Sprintf("%v [budgeted]", self.strategy)
+}
+
+// Counter plays StayAtK with whichever k does best head to head against a
+// reference strategy. If the reference is Tunable with a parameter k, only
+// thresholds within 10 of it are tried; otherwise every k up to win/2 is.
+type Counter struct {
+	ref Strategy
+	k   int
+}
+
+// NewCounter returns a Counter to ref, trying each threshold over games
+// games seeded with seed.
+func NewCounter(ref Strategy, games int, seed int64) *Counter {
+	lo, hi := 1, win/2
+	if t, ok := ref.(Tunable); ok {
+		for _, p := range t.Params() {
+			if p.Name == "k" {
+				lo, hi = max(int(p.Min), int(p.Value)-10), min(int(p.Max), int(p.Value)+10)
+			}
+		}
+	}
+	c := &Counter{ref: ref, k: lo}
+	best := -1.0
+	for k := lo; k <= hi; k++ {
+		if r := CompareStrategies(&StayAtK{k}, ref, games, seed).winRateA(); r > best {
+			c.k, best = k, r
+		}
+	}
+	return c
+}
+
+func (self *Counter) nextAction(s score) action {
+	if s.thisTurn >= self.k {
+		return stay
+	}
+	return roll
+}
+
+func (self *Counter) String() string {
+	return fmt.Sprintf("Counter(%v)", self.ref)
+}
+
+// WeightedRandom is like Random, but rolls with probability p rather than
+// one half, from its own source.
+type WeightedRandom struct {
+	p   float64
+	rng *rand.Rand
+}
+
+// NewWeightedRandom returns a WeightedRandom that rolls with probability p,
+// drawing from a source seeded with seed. It returns an error unless p is
+// between 0 and 1.
+func NewWeightedRandom(p float64, seed int64) (*WeightedRandom, error) {
+	if !(p >= 0 && p <= 1) {
+		return nil, fmt.Errorf("roll probability %g is not between 0 and 1", p)
+	}
+	return &WeightedRandom{p, rand.New(rand.NewSource(seed))}, nil
+}
+
+func (self *WeightedRandom) nextAction(s score) action {
+	if self.rng.Float64() < self.p {
+		return roll
+	}
+	return stay
+}
+
+func (self *WeightedRandom) Seed(seed int64) {
+	self.rng.Seed(seed)
+}
+
+// Clone returns a WeightedRandom with its own source, seeded from self's.
+func (self *WeightedRandom) Clone() Strategy {
+	return &WeightedRandom{self.p, rand.New(rand.NewSource(self.rng.Int63()))}
+}
+
+func (self *WeightedRandom) String() string {
+	return fmt.Sprintf("Random(p=%g)", self.p)
+}
+
+// SmartEV rolls while rolling gains points on average, which on a six-sided
+// die is while thisTurn is below 20. But once its opponent is within
+// panicThreshold points of winning, it rolls until it can win outright.
+type SmartEV struct {
+	panicThreshold int
+}
+
+func (self *SmartEV) nextAction(s score) action {
+	if s.opponent >= win-self.panicThreshold || s.thisTurn < 20 {
+		return roll
+	}
+	return stay
+}
+
+func (self *SmartEV) String() string {
+	return "SmartEV"
+}
+
+// RatioPush rolls while its opponent's distance to winning is less than
+// ratio times its own, counting thisTurn, so it pushes harder the closer
+// its opponent is by comparison. It always rolls at the start of a turn,
+// since staying then gains nothing.
+type RatioPush struct {
+	ratio float64
+}
+
+func (self *RatioPush) nextAction(s score) action {
+	if s.thisTurn == 0 || float64(win-s.opponent) < self.ratio*float64(win-s.player-s.thisTurn) {
+		return roll
+	}
+	return stay
+}
+
+func (self *RatioPush) String() string {
+	return fmt.Sprintf("RatioPush(%g)", self.ratio)
+}
+
+// Cautious projects how many turns each player needs to win, assuming each
+// turn banks pointsPerTurn points on average. While banking thisTurn would
+// leave it needing no more turns than its opponent, it can afford to play
+// safe, and stays at 20. Otherwise it is running out of turns, and keeps
+// rolling until it has caught up.
+type Cautious struct {
+	pointsPerTurn float64
+}
+
+// turnsToWin returns how many turns a player needs to score points more.
+func (self *Cautious) turnsToWin(points int) float64 {
+	return math.Ceil(float64(points) / self.pointsPerTurn)
+}
+
+func (self *Cautious) nextAction(s score) action {
+	if self.turnsToWin(win-s.player-s.thisTurn) > self.turnsToWin(win-s.opponent) || s.thisTurn < 20 {
+		return roll
+	}
+	return stay
+}
+
+func (self *Cautious) String() string {
+	return "Cautious"
+}
+
+// Majority asks each of an odd number of members for its action and rolls
+// if most of them would roll, otherwise staying.
+type Majority struct {
+	members []Strategy
+}
+
+// NewMajority returns a Majority of members, of which there must be an odd
+// number so that there is always a majority.
+func NewMajority(members []Strategy) (*Majority, error) {
+	if len(members)%2 == 0 {
+		return nil, fmt.Errorf("majority needs an odd number of members, not %d", len(members))
+	}
+	return &Majority{members}, nil
+}
+
+func (self *Majority) NextActionAt(g GameState) action {
+	rolls := 0
+	for _, member := range self.members {
+		if isRoll(actionAt(member, g)) {
+			rolls++
+		}
+	}
+	if 2*rolls > len(self.members) {
+		return roll
+	}
+	return stay
+}
+
+func (self *Majority) nextAction(s score) action {
+	return self.NextActionAt(GameState{score: s})
+}
+
+// Seed reseeds each member that is a Seeder from seed.
+func (self *Majority) Seed(seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+	for _, member := range self.members {
+		if seeder, ok := member.(Seeder); ok {
+			seeder.Seed(rng.Int63())
+		}
+	}
+}
+
+func (self *Majority) Clone() Strategy {
+	return &Majority{cloneAll(self.members)}
+}
+
+func (self *Majority) String() string {
+	parts := make([]string, len(self.members))
+	for i, member := range self.members {
+		parts[i] = member.String()
+	}
+	return "Majority(" + strings.Join(parts, ", ") + ")"
+}
+
+// LogisticStrategy rolls when a logistic model of the score predicts that
+// rolling is right with probability over one half. The model's weights are
+// a bias followed by the coefficients of player, opponent and thisTurn.
+type LogisticStrategy struct {
+	weights [4]float64
+}
+
+// NewLogisticStrategy returns a LogisticStrategy with the given weights,
+// for instance as trained elsewhere.
+func NewLogisticStrategy(weights [4]float64) *LogisticStrategy {
+	return &LogisticStrategy{weights}
+}
+
+// rollProbability returns the model's probability that rolling is right.
+func (self *LogisticStrategy) rollProbability(s score) float64 {
+	w := self.weights
+	z := w[0] + w[1]*float64(s.player) + w[2]*float64(s.opponent) + w[3]*float64(s.thisTurn)
+	return 1 / (1 + math.Exp(-z))
+}
+
+func (self *LogisticStrategy) nextAction(s score) action {
+	if self.rollProbability(s) > 0.5 {
+		return roll
+	}
+	return stay
+}
+
+func (self *LogisticStrategy) String() string {
+	w := self.weights
+	return fmt.Sprintf("Logistic(%g, %g, %g, %g)", w[0], w[1], w[2], w[3])
+}
+
+// TableStrategy is like StayAtK, but takes k from a table indexed by its
+// banked score. Scores past the end of the table, or whose entry is 0, use
+// the fallback threshold.
+type TableStrategy struct {
+	thresholds []int
+	fallback   int
+}
+
+// NewTableStrategy returns a TableStrategy that stays at thresholds[p] when
+// its banked score is p, and otherwise at fallback.
+func NewTableStrategy(thresholds []int, fallback int) *TableStrategy {
+	return &TableStrategy{thresholds, fallback}
+}
+
+// threshold returns the threshold to play at with a banked score of player.
+func (self *TableStrategy) threshold(player int) int {
+	if player < len(self.thresholds) && self.thresholds[player] > 0 {
+		return self.thresholds[player]
+	}
+	return self.fallback
+}
+
+func (self *TableStrategy) nextAction(s score) action {
+	if s.thisTurn >= self.threshold(s.player) {
+		return stay
+	}
+	return roll
+}
+
+func (self *TableStrategy) String() string {
+	return "Table"
+}
+
+// RiskAverse is like SmartEV without the close-out, rolling while a roll
+// gains points on average, except that while banking thisTurn would put it
+// ahead, it counts lambda times the variance of a roll's gain against it.
+// So when ahead, the higher lambda is, the earlier it stays.
+type RiskAverse struct {
+	lambda float64
+}
+
+func (self *RiskAverse) nextAction(s score) action {
+	// A roll loses thisTurn with probability 1/6 and otherwise gains 2 to 6.
+	t := float64(s.thisTurn)
+	mean := (20 - t) / 6
+	utility := mean
+	if s.player+s.thisTurn > s.opponent {
+		variance := (t*t+90)/6 - mean*mean
+		utility -= self.lambda * variance
+	}
+	if utility > 0 {
+		return roll
+	}
+	return stay
+}
+
+func (self *RiskAverse) String() string {
+	return fmt.Sprintf("RiskAverse(%g)", self.lambda)
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCappedStayAtKStaysOnceItCanWin(t *testing.T) {
+	capped, plain := &CappedStayAtK{20, win}, &StayAtK{20}
+	for _, s := range []score{{90, 50, 10}, {98, 0, 2}, {99, 99, 5}} {
+		if isRoll(capped.nextAction(s)) {
+			t.Errorf("CappedStayAtK rolled at %+v, though staying wins", s)
+		}
+		if !isRoll(plain.nextAction(s)) {
+			t.Errorf("StayAtK stayed at %+v, want it to roll below k", s)
+		}
+	}
+	for _, s := range []score{{50, 50, 10}, {80, 0, 19}} {
+		if !isRoll(capped.nextAction(s)) {
+			t.Errorf("CappedStayAtK stayed at %+v, short of both k and the target", s)
+		}
+	}
+}
+
+// slow always rolls, after sleeping for delay.
+type slow struct {
+	delay time.Duration
+}
+
+func (self *slow) nextAction(s score) action {
+	time.Sleep(self.delay)
+	return roll
+}
+
+func (self *slow) String() string {
+	return "slow"
+}
+
+func TestTimeBudgetStaysWhenTooSlow(t *testing.T) {
+	s := score{0, 0, 10}
+	if a := (&TimeBudget{&slow{0}, time.Second}).nextAction(s); !isRoll(a) {
+		t.Error("a strategy within its budget stayed, want its roll")
+	}
+	start := time.Now()
+	if a := (&TimeBudget{&slow{time.Second}, 10 * time.Millisecond}).nextAction(s); isRoll(a) {
+		t.Error("a strategy over its budget rolled, want the fallback stay")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("deciding took %v, want about the 10ms budget", elapsed)
+	}
+}
+
+func TestLeadTargetStaysAtTheGoal(t *testing.T) {
+	lead := &LeadTarget{15}
+	for _, opponent := range []int{0, 30, 60, 90} {
+		player := 20
+		flip := opponent + 15 - player
+		for thisTurn := max(0, flip-3); thisTurn <= flip+3; thisTurn++ {
+			s := score{player, opponent, thisTurn}
+			if rolled, want := isRoll(lead.nextAction(s)), thisTurn < flip; rolled != want {
+				t.Errorf("at %+v rolled=%v, want %v", s, rolled, want)
+			}
+		}
+	}
+}
+
+func TestCounterBeatsItsReference(t *testing.T) {
+	ref := &StayAtK{10}
+	c := NewCounter(ref, 2000, 1)
+	if c.k <= ref.k {
+		t.Errorf("Counter to StayAtK{10} stays at %d, want a higher threshold", c.k)
+	}
+	// Fresh dice, so that the win isn't just the luck c.k was picked on.
+	if r := CompareStrategies(c, ref, 5000, 2).winRateA(); r <= 0.55 {
+		t.Errorf("Counter(k=%d) wins %.3f head to head against StayAtK{10}, want clearly more than half", c.k, r)
+	}
+}
+
+func TestWeightedRandomRollsWithProbabilityP(t *testing.T) {
+	const decisions = 20000
+	for _, p := range []float64{0, 0.2, 0.5, 0.9, 1} {
+		w, err := NewWeightedRandom(p, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rolls := 0
+		for i := 0; i < decisions; i++ {
+			if isRoll(w.nextAction(score{0, 0, 10})) {
+				rolls++
+			}
+		}
+		if got := float64(rolls) / decisions; math.Abs(got-p) > 0.02 {
+			t.Errorf("p=%g: rolled %.3f of the time", p, got)
+		}
+	}
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		if _, err := NewWeightedRandom(p, 1); err == nil {
+			t.Errorf("p=%g was accepted", p)
+		}
+	}
+}
+
+func TestSmartEVBeatsItsParts(t *testing.T) {
+	// The expected-value rule alone stays at 20, and the close-out rule
+	// alone rolls until it can win outright.
+	smart, expectedValue, closeOut := &SmartEV{20}, &StayAtK{20}, &StayAtK{win}
+	r := Simulate([]Strategy{smart, expectedValue, closeOut}, 5000, 1)
+	if rank := ranks(r.Standings)[0]; rank != 1 {
+		t.Errorf("SmartEV ranked %d in %v, want first", rank, r.Standings)
+	}
+	for _, opponent := range []Strategy{expectedValue, closeOut} {
+		if c := CompareStrategies(smart, opponent, 5000, 2); c.winRateA() <= 0.52 {
+			t.Errorf("SmartEV won %+v against %v, want more than half", c, opponent)
+		}
+	}
+}
+
+func TestRatioPushPushesWhenBehind(t *testing.T) {
+	push := &RatioPush{1}
+	differs := false
+	for thisTurn := 2; thisTurn < 20; thisTurn++ {
+		behind := isRoll(push.nextAction(score{20, 70, thisTurn}))
+		ahead := isRoll(push.nextAction(score{70, 20, thisTurn}))
+		if ahead && !behind {
+			t.Errorf("on %d it rolls when ahead but stays when behind", thisTurn)
+		}
+		differs = differs || behind && !ahead
+	}
+	if !differs {
+		t.Error("it plays the same ahead and behind")
+	}
+	if !isRoll(push.nextAction(score{70, 20, 0})) {
+		t.Error("it stayed at the start of a turn")
+	}
+}
+
+func TestCautiousPushesOnlyWhenRunningOutOfTurns(t *testing.T) {
+	c := &Cautious{10}
+	for _, test := range []struct {
+		s    score
+		roll bool
+	}{
+		{score{50, 50, 10}, true},  // below 20
+		{score{50, 50, 25}, false}, // banking leaves 3 turns to the opponent's 5
+		{score{50, 30, 25}, false}, // 3 turns to 7
+		{score{10, 80, 25}, true},  // 7 turns to 2
+		{score{10, 80, 60}, true},  // 3 turns to 2
+		{score{10, 80, 70}, false}, // 2 turns to 2: caught up
+	} {
+		if isRoll(c.nextAction(test.s)) != test.roll {
+			t.Errorf("at %+v rolled=%v, want %v", test.s, !test.roll, test.roll)
+		}
+	}
+}
+
+func TestMajorityFollowsMostMembers(t *testing.T) {
+	// At 15, StayAtK{20} and StayAtK{30} roll, and StayAtK{10} stays.
+	s := score{0, 0, 15}
+	m, err := NewMajority([]Strategy{&StayAtK{20}, &StayAtK{10}, &StayAtK{30}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRoll(m.nextAction(s)) {
+		t.Error("two members rolling and one staying made it stay")
+	}
+	m, _ = NewMajority([]Strategy{&StayAtK{10}, &StayAtK{5}, &StayAtK{30}})
+	if isRoll(m.nextAction(s)) {
+		t.Error("one member rolling and two staying made it roll")
+	}
+	if _, err := NewMajority([]Strategy{&StayAtK{10}, &StayAtK{30}}); err == nil {
+		t.Error("a majority of two members was allowed")
+	}
+}
+
+func TestLogisticStrategyCanHoldAt20(t *testing.T) {
+	// Rolls while 19.5 - thisTurn > 0, that is below 20.
+	logistic := NewLogisticStrategy([4]float64{19.5, 0, 0, -1})
+	for _, s := range ReachableStates(win) {
+		if got, want := isRoll(logistic.nextAction(s)), s.thisTurn < 20; got != want {
+			t.Fatalf("at %+v the logistic strategy rolls: %v, want %v", s, got, want)
+		}
+	}
+	got := CompareStrategies(logistic, &StayAtK{25}, 1000, 1)
+	if want := CompareStrategies(&StayAtK{20}, &StayAtK{25}, 1000, 1); got != want {
+		t.Errorf("the logistic strategy did %+v against StayAtK{25}, want %+v like StayAtK{20}", got, want)
+	}
+}
+
+// meanMargin returns a's win rate against b over games games, alternating
+// who moves first, and its mean final score less b's.
+func meanMargin(a, b Strategy, games int) (winRate, margin float64) {
+	won, total := 0, 0
+	for i := 0; i < games; i++ {
+		d := PlayDetailed(a, b, i%2, int64(i))
+		if d.Winner == 0 {
+			won++
+		}
+		total += d.Scores[0] - d.Scores[1]
+	}
+	return float64(won) / float64(games), float64(total) / float64(games)
+}
+
+func TestMaxMarginTradesWinsForMargin(t *testing.T) {
+	winRate, margin := meanMargin(&MaxMargin{}, &Optimal{}, 20000)
+	if margin <= 0 || winRate >= 0.5 {
+		t.Errorf("MaxMargin won %.3f of its games against Optimal by %.2f on average, want fewer than half by more than 0",
+			winRate, margin)
+	}
+	maxRate, maxAhead := meanMargin(&MaxMargin{}, &StayAtK{20}, 20000)
+	optRate, optAhead := meanMargin(&Optimal{}, &StayAtK{20}, 20000)
+	if maxAhead <= optAhead+1 {
+		t.Errorf("against StayAtK{20} MaxMargin finished %.2f ahead on average and Optimal %.2f, want MaxMargin further ahead",
+			maxAhead, optAhead)
+	}
+	if maxRate > optRate {
+		t.Errorf("against StayAtK{20} MaxMargin won %.3f of its games and Optimal %.3f, want Optimal to win more", maxRate, optRate)
+	}
+}
+
+func TestTableStrategyThresholds(t *testing.T) {
+	table := NewTableStrategy([]int{10, 0, 30}, 20)
+	for _, c := range []struct {
+		player, stayAt int
+	}{{0, 10}, {1, 20}, {2, 30}, {3, 20}, {80, 20}} {
+		before, at := score{c.player, 0, c.stayAt - 1}, score{c.player, 0, c.stayAt}
+		if !isRoll(table.nextAction(before)) || isRoll(table.nextAction(at)) {
+			t.Errorf("with %d banked the table strategy doesn't stay at %d", c.player, c.stayAt)
+		}
+	}
+}
+
+// marginSpread returns the standard deviation of s's margin in the games it
+// wins of games games against StayAtK{20}, alternating who moves first.
+func marginSpread(s Strategy, games int) float64 {
+	var margins []float64
+	for i := 0; i < games; i++ {
+		if d := PlayDetailed(s, &StayAtK{20}, i%2, int64(i)); d.Winner == 0 {
+			margins = append(margins, float64(d.Margin()))
+		}
+	}
+	return meanStdDev(margins).StdDev
+}
+
+func TestRiskAverseStaysEarlierAsLambdaRises(t *testing.T) {
+	// stayAt returns the turn total at which s first stays, 40 points ahead.
+	stayAt := func(s Strategy) int {
+		k := 0
+		for isRoll(actionAt(s, GameState{score{50, 10, k}, 1})) {
+			k++
+		}
+		return k
+	}
+	previous := stayAt(&RiskAverse{0})
+	if expectedValue := stayAt(&SmartEV{20}); previous != expectedValue {
+		t.Errorf("RiskAverse(0) stays at %d when ahead, want %d like SmartEV", previous, expectedValue)
+	}
+	for _, lambda := range []float64{0.01, 0.05, 0.2} {
+		k := stayAt(&RiskAverse{lambda})
+		if k >= previous {
+			t.Errorf("RiskAverse(%g) stays at %d when ahead, want earlier than %d with a lower lambda", lambda, k, previous)
+		}
+		previous = k
+	}
+	if !isRoll((&RiskAverse{0.2}).nextAction(score{10, 50, 15})) {
+		t.Error("RiskAverse(0.2) stays at 15 while behind, want it to ignore the risk")
+	}
+
+	expectedValue := marginSpread(&SmartEV{20}, 20000)
+	for _, lambda := range []float64{0.05, 0.2} {
+		if spread := marginSpread(&RiskAverse{lambda}, 20000); spread >= expectedValue {
+			t.Errorf("RiskAverse(%g)'s winning margins spread by %.1f and SmartEV's by %.1f, want RiskAverse's narrower",
+				lambda, spread, expectedValue)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// A MatchResult is the outcome of a series of games between two strategies.
+type MatchResult struct {
+	A, B         Strategy
+	I, J         int // The positions of A and B in the lineup
+	WinsA, WinsB int
+}
+
+// RoundRobinStream simulates a series of games between every pair of
+// strategies, like roundRobin, but sends the result of each series as soon as
+// it is done. The channel is closed once every series has been sent. The
+// consumer must drain the channel; use RoundRobinStreamContext to stop early.
+func RoundRobinStream(strategies []Strategy) <-chan MatchResult {
+	return RoundRobinStreamContext(context.Background(), strategies)
+}
+
+// RoundRobinStreamContext is like RoundRobinStream, but stops simulating and
+// closes the channel once ctx is done, so a consumer may stop reading by
+// cancelling ctx.
+func RoundRobinStreamContext(ctx context.Context, strategies []Strategy) <-chan MatchResult {
+	return streamRoundRobin(ctx, strategies, func(i, j int) int {
+		return gamesPerSeries
+	})
+}
+
+// streamRoundRobin is like RoundRobinStreamContext, but plays games(i, j)
+// games in the series between strategies i and j.
+func streamRoundRobin(ctx context.Context, strategies []Strategy, games func(i, j int) int) <-chan MatchResult {
+	results := make(chan MatchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < len(strategies); i++ {
+		wg.Add(1)
+		go func(i int, players []Strategy) {
+			defer wg.Done()
+			for j := i + 1; j < len(strategies); j++ {
+				r := MatchResult{A: strategies[i], B: strategies[j], I: i, J: j}
+				reset(players[i], players[j])
+				for k := games(i, j); k > 0; k-- {
+					if play(players[i], players[j]) == 0 {
+						r.WinsA++
+					} else {
+						r.WinsB++
+					}
+				}
+				select {
+				case results <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(i, cloneAll(strategies))
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+// RoundRobinWeighted is like roundRobin, but plays gamesFunc(i, j) games in
+// the series between strategies i and j. It returns each strategy's
+// standing, counting only the games it actually played, along with the
+// result of every series.
+func RoundRobinWeighted(strategies []Strategy, gamesFunc func(i, j int) int) ([]Standing, []MatchResult) {
+	standings := make([]Standing, len(strategies))
+	for i, strategy := range strategies {
+		standings[i].Strategy = strategy
+	}
+	var series []MatchResult
+	for r := range streamRoundRobin(context.Background(), strategies, gamesFunc) {
+		standings[r.I].Wins += r.WinsA
+		standings[r.I].Losses += r.WinsB
+		standings[r.J].Wins += r.WinsB
+		standings[r.J].Losses += r.WinsA
+		series = append(series, r)
+	}
+	return standings, series
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRoundRobinStreamContextCancelLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	strategies := make([]Strategy, 20)
+	for i := range strategies {
+		strategies[i] = &StayAtK{i + 10}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	results := RoundRobinStreamContext(ctx, strategies)
+	for i := 0; i < 3; i++ {
+		<-results
+	}
+	cancel()
+	for range results {
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running after cancelling, %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRoundRobinWeightedPlaysTheRequestedGames(t *testing.T) {
+	lineup := []Strategy{&StayAtK{15}, &StayAtK{20}, &StayAtK{25}, &StayAtK{30}}
+	games := func(i, j int) int { return 10 * (i + 1) * (j + 1) }
+	standings, series := RoundRobinWeighted(lineup, games)
+	if len(series) != 6 {
+		t.Fatalf("got %d series, want 6", len(series))
+	}
+	played := make([]int, len(lineup))
+	for _, s := range series {
+		if s.WinsA+s.WinsB != games(s.I, s.J) {
+			t.Errorf("%v and %v played %d games, want %d", s.A, s.B, s.WinsA+s.WinsB, games(s.I, s.J))
+		}
+		played[s.I] += games(s.I, s.J)
+		played[s.J] += games(s.I, s.J)
+	}
+	for i, st := range standings {
+		if st.Wins+st.Losses != played[i] {
+			t.Errorf("%v is credited with %d games, want %d", st.Strategy, st.Wins+st.Losses, played[i])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// A SweepPoint is a strategy's win rate with one parameter set to Value.
+type SweepPoint struct {
+	Value   int
+	WinRate float64
+}
+
+// SweepParameter builds the registered strategy base with param set to each
+// value from from to to, and returns each one's win rate against opponent
+// over games games seeded with seed.
+func SweepParameter(base string, param string, from, to int, opponent Strategy, games int, seed int64) ([]SweepPoint, error) {
+	var points []SweepPoint
+	for v := from; v <= to; v++ {
+		s, err := buildStrategy(base, []string{fmt.Sprintf("%s=%d", param, v)})
+		if err != nil {
+			return nil, err
+		}
+		c := CompareStrategies(s, opponent, games, seed)
+		points = append(points, SweepPoint{v, c.winRateA()})
+	}
+	return points, nil
+}
+
+// Heatmap2D sweeps two of proto's parameters, paramX over the inclusive
+// range xRange and paramY over yRange, leaving the others as they are, and
+// returns the grid of win rates against opponent over games games seeded
+// with seed, where [i][j] is the win rate with paramX set to xRange[0]+i
+// and paramY to yRange[0]+j. It returns nil if proto has no parameter
+// named paramX or paramY.
+func Heatmap2D(proto Tunable, paramX, paramY string, xRange, yRange [2]int, opponent Strategy, games int, seed int64) [][]float64 {
+	x, y := -1, -1
+	for i, p := range proto.Params() {
+		switch p.Name {
+		case paramX:
+			x = i
+		case paramY:
+			y = i
+		}
+	}
+	if x < 0 || y < 0 {
+		return nil
+	}
+	var grid [][]float64
+	for vx := xRange[0]; vx <= xRange[1]; vx++ {
+		row := make([]float64, 0, max(0, yRange[1]-yRange[0]+1))
+		for vy := yRange[0]; vy <= yRange[1]; vy++ {
+			values := paramValues(proto.Params())
+			values[x], values[y] = float64(vx), float64(vy)
+			row = append(row, CompareStrategies(proto.WithParams(values), opponent, games, seed).winRateA())
+		}
+		grid = append(grid, row)
+	}
+	return grid
+}
+
+// runSweep runs the sweep described by spec, such as
+// "stayat:k=1..30 vs random", and writes a table of parameter values and win
+// rates to w.
+func runSweep(w io.Writer, spec string, games int, seed int64) error {
+	sweep, against, ok := strings.Cut(spec, " vs ")
+	base, assignment, ok2 := strings.Cut(sweep, ":")
+	param, span, ok3 := strings.Cut(assignment, "=")
+	first, last, ok4 := strings.Cut(span, "..")
+	if !ok || !ok2 || !ok3 || !ok4 {
+		return fmt.Errorf("sweep %q is not of the form name:param=from..to vs opponent", spec)
+	}
+	from, err := strconv.Atoi(first)
+	if err != nil {
+		return fmt.Errorf("sweep %q: bad start %q", spec, first)
+	}
+	to, err := strconv.Atoi(last)
+	if err != nil {
+		return fmt.Errorf("sweep %q: bad end %q", spec, last)
+	}
+	opponent, err := ParseStrategyExpr(against)
+	if err != nil {
+		return err
+	}
+	points, err := SweepParameter(base, param, from, to, opponent, games, seed)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s\twin rate vs %v\n", param, opponent)
+	for _, p := range points {
+		fmt.Fprintf(w, "%d\t%0.3f\n", p.Value, p.WinRate)
+	}
+	return nil
+}
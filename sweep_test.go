@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSweepStayAtKAgainstRandomPeaksInTheMiddle(t *testing.T) {
+	points, err := SweepParameter("stayat", "k", 1, 30, NewRandom(1), 2000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 30 {
+		t.Fatalf("got %d points, want one per k from 1 to 30", len(points))
+	}
+	best := points[0]
+	for _, p := range points {
+		if p.WinRate > best.WinRate {
+			best = p
+		}
+	}
+	if best.Value < 10 || best.Value > 25 {
+		t.Errorf("StayAtK does best against Random with k=%d, want it between 10 and 25", best.Value)
+	}
+	if points[0].WinRate >= best.WinRate-0.1 {
+		t.Errorf("k=1 wins %.3f, want it well below the best %.3f", points[0].WinRate, best.WinRate)
+	}
+	if last := points[len(points)-1]; last.WinRate >= best.WinRate {
+		t.Errorf("k=30 wins %.3f, want it below the best %.3f", last.WinRate, best.WinRate)
+	}
+}
+
+func TestHeatmap2DPeaksInside(t *testing.T) {
+	proto := &Adaptive{25, 20, 15}
+	grid := Heatmap2D(proto, "behind", "ahead", [2]int{10, 40}, [2]int{5, 35}, &StayAtK{20}, 200, 1)
+	if len(grid) != 31 {
+		t.Fatalf("the grid has %d rows, want one for each behind from 10 to 40", len(grid))
+	}
+	for i, row := range grid {
+		if len(row) != 31 {
+			t.Fatalf("row %d of the grid has %d cells, want one for each ahead from 5 to 35", i, len(row))
+		}
+	}
+	corners := max(grid[0][0], grid[0][30], grid[30][0], grid[30][30])
+	best := 0.0
+	for _, row := range grid[1:30] {
+		best = max(best, slices.Max(row[1:30]))
+	}
+	if best <= corners {
+		t.Errorf("the best win rate inside the grid is %.3f, want more than the best corner's %.3f", best, corners)
+	}
+	if want := CompareStrategies(&Adaptive{12, 20, 8}, &StayAtK{20}, 200, 1).winRateA(); grid[2][3] != want {
+		t.Errorf("the cell for behind 12 and ahead 8 is %f, want %f", grid[2][3], want)
+	}
+	if Heatmap2D(proto, "behind", "nonsense", [2]int{10, 12}, [2]int{5, 7}, &StayAtK{20}, 10, 1) != nil {
+		t.Error("a heatmap over a parameter Adaptive doesn't have was drawn")
+	}
+}
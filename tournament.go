@@ -0,0 +1,235 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// SurvivalTournament plays rounds in which every remaining pair of strategies
+// plays gamesPerRound games, the series between the i-th and j-th remaining
+// strategies of round r seeded with seriesSeed(seed+r, i, j) as by
+// seededSeries. The series are played by clones of strategies, made once
+// for the whole tournament. After each round, a strategy's margin of loss
+// is its opponents' final score minus its own, averaged over all of its
+// games in the round. Every strategy whose margin exceeds marginThreshold
+// is eliminated, worst first and ties broken by name, except that the best
+// of them survives if that would eliminate everyone, and the worst is
+// eliminated if nobody exceeds it. The rounds go on until one strategy
+// remains.
+func SurvivalTournament(strategies []Strategy, gamesPerRound int, marginThreshold int, seed int64) (survivor Strategy, eliminatedOrder []Strategy) {
+	players := cloneAll(strategies)
+	// remaining holds the indices of the strategies still in.
+	remaining := make([]int, len(strategies))
+	for i := range remaining {
+		remaining[i] = i
+	}
+	for round := int64(0); len(remaining) > 1; round++ {
+		margins := make([]float64, len(remaining))
+		for i := range remaining {
+			for j := i + 1; j < len(remaining); j++ {
+				a, b := players[remaining[i]], players[remaining[j]]
+				rng := rand.New(rand.NewSource(reseed(a, b, seriesSeed(seed+round, i, j)).Int63()))
+				dice := GameConfig{}.die(rng)
+				for g := 0; g < gamesPerRound; g++ {
+					d := playDetailed(GameConfig{}, a, b, g%2, dice, nil)
+					loss := float64(d.Scores[1] - d.Scores[0])
+					margins[i] += loss
+					margins[j] -= loss
+				}
+			}
+		}
+		games := float64(gamesPerRound * (len(remaining) - 1))
+		order := make([]int, len(remaining))
+		for i := range order {
+			order[i] = i
+			margins[i] /= games
+		}
+		// Worst first, ties broken by name.
+		sort.Slice(order, func(x, y int) bool {
+			a, b := order[x], order[y]
+			if margins[a] != margins[b] {
+				return margins[a] > margins[b]
+			}
+			return strategies[remaining[a]].String() < strategies[remaining[b]].String()
+		})
+		out := 0
+		for out < len(order) && margins[order[out]] > float64(marginThreshold) {
+			out++
+		}
+		if out == 0 {
+			out = 1
+		} else if out == len(order) {
+			out--
+		}
+		eliminated := make(map[int]bool)
+		for _, i := range order[:out] {
+			eliminatedOrder = append(eliminatedOrder, strategies[remaining[i]])
+			eliminated[i] = true
+		}
+		var next []int
+		for i, k := range remaining {
+			if !eliminated[i] {
+				next = append(next, k)
+			}
+		}
+		remaining = next
+	}
+	if len(remaining) == 1 {
+		survivor = strategies[remaining[0]]
+	}
+	return survivor, eliminatedOrder
+}
+
+// seedOrder returns the indices of strategies from best seed to worst: those
+// rated in seeds, from the highest rating down, then the rest in order.
+func seedOrder(strategies []Strategy, seeds map[string]float64) []int {
+	order := make([]int, len(strategies))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		a, aRated := seeds[strategies[order[x]].String()]
+		b, bRated := seeds[strategies[order[y]].String()]
+		if aRated != bRated {
+			return aRated
+		}
+		return aRated && a > b
+	})
+	return order
+}
+
+// bracketSlots returns the seeds, counting from 0, in the order they are
+// placed in a single-elimination bracket with size slots, a power of 2, so
+// that while seeds 0 and 1 win, they only meet in the final, seeds 0 to 3
+// only in the semifinals or later, and so on.
+func bracketSlots(size int) []int {
+	slots := []int{0}
+	for n := 1; n < size; n *= 2 {
+		next := make([]int, 0, 2*n)
+		for _, s := range slots {
+			next = append(next, s, 2*n-1-s)
+		}
+		slots = next
+	}
+	return slots
+}
+
+// EliminationTournament plays a single-elimination tournament between
+// strategies, in which each match is a series of games games and the
+// strategy with more wins goes through, the better seed on a tie. Matches
+// are played by clones of strategies, and in round r each is played as a
+// series of a round robin seeded with seed+r would be by Simulate.
+//
+// Strategies are seeded by their rating in seeds, keyed by name, with the
+// highest rated as the top seed; those without a rating are seeded below
+// the others, in order. The bracket keeps the top seeds apart until the
+// late rounds, and if the number of strategies isn't a power of 2, the top
+// seeds get byes in the first round. It returns the champion and the
+// matches of each round.
+func EliminationTournament(strategies []Strategy, games int, seed int64, seeds map[string]float64) (champion Strategy, rounds [][]MatchResult) {
+	if len(strategies) == 0 {
+		return nil, nil
+	}
+	order := seedOrder(strategies, seeds)
+	players := cloneAll(strategies)
+	size := 1
+	for size < len(order) {
+		size *= 2
+	}
+	// bracket holds the remaining seeds in bracket order, -1 for a bye.
+	var bracket []int
+	for _, s := range bracketSlots(size) {
+		if s >= len(order) {
+			s = -1
+		}
+		bracket = append(bracket, s)
+	}
+	for r := int64(0); len(bracket) > 1; r++ {
+		var matches []MatchResult
+		next := make([]int, len(bracket)/2)
+		for m := range next {
+			x, y := bracket[2*m], bracket[2*m+1]
+			if x < 0 || y < 0 {
+				next[m] = max(x, y)
+				continue
+			}
+			if y < x {
+				x, y = y, x
+			}
+			i, j, series := pairSeries(seed+r, players, order[x], order[y])
+			c := seededSeries(players[i], players[j], games, series)
+			matches = append(matches, MatchResult{strategies[i], strategies[j], i, j, c.WinsA, c.WinsB})
+			winsX, winsY := c.WinsA, c.WinsB
+			if i != order[x] {
+				winsX, winsY = winsY, winsX
+			}
+			next[m] = x
+			if winsY > winsX {
+				next[m] = y
+			}
+		}
+		rounds = append(rounds, matches)
+		bracket = next
+	}
+	return strategies[order[bracket[0]]], rounds
+}
+
+// SwissTournament plays rounds rounds of a Swiss-system tournament between
+// strategies, in which each match is a series of games games. Before each
+// round, the strategies are ranked by the games they have won so far, ties
+// in order of name. If there are an odd number, the lowest ranked strategy
+// that has not yet sat out a round sits this one out. Then from the top
+// down, each unpaired strategy is paired with the next unpaired one below
+// it that it has not yet played, if any; a strategy left without an
+// opponent also sits the round out. Matches are played by clones of
+// strategies, seeded as in EliminationTournament, so the results don't
+// depend on the order of the lineup. It returns the matches of each round.
+func SwissTournament(strategies []Strategy, games, rounds int, seed int64) [][]MatchResult {
+	players := cloneAll(strategies)
+	wins := make([]int, len(strategies))
+	played := make(map[[2]int]bool)
+	byes := make([]bool, len(strategies))
+	var results [][]MatchResult
+	for r := 0; r < rounds; r++ {
+		order := make([]int, len(strategies))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(x, y int) bool {
+			if wins[order[x]] != wins[order[y]] {
+				return wins[order[x]] > wins[order[y]]
+			}
+			return strategies[order[x]].String() < strategies[order[y]].String()
+		})
+		paired := make([]bool, len(strategies))
+		if len(order)%2 == 1 {
+			for x := len(order) - 1; x >= 0; x-- {
+				if i := order[x]; !byes[i] {
+					paired[i], byes[i] = true, true
+					break
+				}
+			}
+		}
+		var matches []MatchResult
+		for x, i := range order {
+			if paired[i] {
+				continue
+			}
+			for _, j := range order[x+1:] {
+				if paired[j] || played[[2]int{min(i, j), max(i, j)}] {
+					continue
+				}
+				paired[i], paired[j] = true, true
+				played[[2]int{min(i, j), max(i, j)}] = true
+				a, b, series := pairSeries(seed+int64(r), players, i, j)
+				c := seededSeries(players[a], players[b], games, series)
+				matches = append(matches, MatchResult{strategies[a], strategies[b], a, b, c.WinsA, c.WinsB})
+				wins[a] += c.WinsA
+				wins[b] += c.WinsB
+				break
+			}
+		}
+		results = append(results, matches)
+	}
+	return results
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSurvivalTournament(t *testing.T) {
+	lineup := []Strategy{&StayAtK{2}, &StayAtK{20}, &StayAtK{60}, &StayAtK{25}, NewRandom(1)}
+	survivor, order := SurvivalTournament(lineup, 200, 5, 1)
+	seen := map[Strategy]bool{survivor: true}
+	for _, s := range order {
+		if seen[s] {
+			t.Errorf("%v was eliminated twice, or after surviving", s)
+		}
+		seen[s] = true
+	}
+	if len(order) != len(lineup)-1 || len(seen) != len(lineup) {
+		t.Errorf("survivor %v and eliminations %v don't account for the lineup %v", survivor, order, lineup)
+	}
+	if survivor != lineup[1] && survivor != lineup[3] {
+		t.Errorf("%v survived, want StayAtK{20} or StayAtK{25}", survivor)
+	}
+	// Random is reseeded for every series, so how it was seeded, or what it
+	// drew in the last tournament, doesn't matter.
+	for _, random := range []Strategy{lineup[4], NewRandom(99)} {
+		rerun := append(lineup[:4:4], random)
+		againSurvivor, againOrder := SurvivalTournament(rerun, 200, 5, 1)
+		if againSurvivor != survivor {
+			t.Errorf("a rerun crowned %v, then %v", survivor, againSurvivor)
+		}
+		sameName := func(a, b Strategy) bool { return a.String() == b.String() }
+		if !slices.EqualFunc(againOrder, order, sameName) {
+			t.Errorf("a rerun eliminated %v, then %v", order, againOrder)
+		}
+	}
+}
+
+func TestEliminationTournamentKeepsTopSeedsApart(t *testing.T) {
+	// The two strongest strategies are the top seeds, listed last so that
+	// the bracket can't just follow the lineup.
+	for _, lineup := range [][]Strategy{
+		{&StayAtK{2}, &StayAtK{3}, &StayAtK{4}, &StayAtK{60}, &StayAtK{70}, &StayAtK{80}, &StayAtK{20}, &StayAtK{25}},
+		{&StayAtK{2}, &StayAtK{3}, &StayAtK{60}, &StayAtK{70}, &StayAtK{20}, &StayAtK{25}},
+	} {
+		seeds := map[string]float64{"Stay at 20": 2000, "Stay at 25": 1900}
+		top := func(s Strategy) bool { return s.String() == "Stay at 20" || s.String() == "Stay at 25" }
+		champion, rounds := EliminationTournament(lineup, 200, 1, seeds)
+		if len(rounds) != 3 {
+			t.Fatalf("%d strategies played %d rounds, want 3", len(lineup), len(rounds))
+		}
+		for r, matches := range rounds[:len(rounds)-1] {
+			for _, m := range matches {
+				if top(m.A) && top(m.B) {
+					t.Errorf("%d strategies: the top seeds met in round %d of %d", len(lineup), r+1, len(rounds))
+				}
+			}
+		}
+		final := rounds[len(rounds)-1]
+		if len(final) != 1 || !top(final[0].A) || !top(final[0].B) {
+			t.Errorf("%d strategies: the final was %+v, want the top seeds", len(lineup), final)
+		}
+		if !top(champion) {
+			t.Errorf("%d strategies: %v won, want a top seed", len(lineup), champion)
+		}
+	}
+}
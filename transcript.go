@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+)
+
+// A Turn records a single action taken during a game.
+type Turn struct {
+	Player    int  `json:"player"`    // The player who acted (0 or 1)
+	Die       int  `json:"die"`       // The total rolled, or 0 if the player stayed
+	TurnTotal int  `json:"turnTotal"` // The player's points this turn after acting
+	Ended     bool `json:"ended"`     // Whether the action ended the turn
+}
+
+// newTurn describes player's action that took the score from before to
+// after, rolling a total of outcome (0 if no die was rolled). When the
+// action ends the turn, TurnTotal is the number of points banked: zero on a
+// bust, or before.thisTurn on a stay.
+func newTurn(player, outcome int, before, after score, turnIsOver bool) Turn {
+	total := after.thisTurn
+	if turnIsOver {
+		total = 0
+		if outcome == 0 {
+			total = before.thisTurn
+		}
+	}
+	return Turn{player, outcome, total, turnIsOver}
+}
+
+// playLogged simulates a Pig game and returns the winner (0 or 1) along with
+// a log of every action taken.
+func playLogged(strategy0, strategy1 Strategy) (int, []Turn) {
+	var log []Turn
+	record := func(t Turn) {
+		log = append(log, t)
+	}
+	// Randomly decide who plays first
+	winner := playGame(GameConfig{}, strategy0, strategy1, rand.Intn(2), sixSided, record)
+	return winner, log
+}
+
+// A Game is the transcript of a single game: its winner and every action
+// taken, in order.
+type Game struct {
+	Winner int    `json:"winner"`
+	Turns  []Turn `json:"turns"`
+}
+
+// MarshalGame returns the JSON encoding of a game won by winner with the
+// given log.
+func MarshalGame(winner int, log []Turn) ([]byte, error) {
+	return json.Marshal(Game{winner, log})
+}
+
+// A GameOutcome is everything about a single game: how it ended, as in
+// GameDetail, and its transcript.
+type GameOutcome struct {
+	Winner     int    `json:"winner"`     // The winning player (0 or 1)
+	Scores     [2]int `json:"scores"`     // Each player's final score, including the winner's last turn
+	Turns      int    `json:"turns"`      // The number of turns started by either player
+	Transcript []Turn `json:"transcript"` // Every action taken, in order
+}
+
+// RunGame simulates a Pig game between a, which moves first, and b, with
+// dice seeded with seed, and returns its outcome. The same strategies and
+// seed always give the same game.
+func RunGame(a, b Strategy, seed int64) GameOutcome {
+	var o GameOutcome
+	record := func(t Turn) {
+		o.Transcript = append(o.Transcript, t)
+	}
+	dice := GameConfig{}.die(rand.New(rand.NewSource(seed)))
+	detail := playDetailed(GameConfig{}, a, b, 0, dice, record)
+	o.Winner, o.Scores, o.Turns = detail.Winner, detail.Scores, detail.Turns
+	return o
+}
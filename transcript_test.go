@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"math/rand"
+	"os"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// AssertGoldenGame plays RunGame(a, b, seed) and fails t unless its outcome,
+// as indented JSON, matches the golden file at goldenPath. With -update it
+// writes the file instead. Golden files lock in exactly how strategies
+// play, so that a change in behavior can't go unnoticed.
+func AssertGoldenGame(t *testing.T, a, b Strategy, seed int64, goldenPath string) {
+	t.Helper()
+	got, err := json.MarshalIndent(RunGame(a, b, seed), "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = append(got, '\n')
+	if *update {
+		if err := os.WriteFile(goldenPath, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(goldenPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%v vs %v with seed %d no longer matches %s; rerun with -update if that's intended:\n%s",
+			a, b, seed, goldenPath, got)
+	}
+}
+
+func TestGoldenGames(t *testing.T) {
+	AssertGoldenGame(t, &StayAtK{20}, NewRandom(1), 1, "testdata/stayat20_vs_random.json")
+	AssertGoldenGame(t, &StayAtK{20}, &StayAtK{25}, 1, "testdata/stayat20_vs_stayat25.json")
+}
+
+func TestMarshalGameRoundTrips(t *testing.T) {
+	var log []Turn
+	dice := GameConfig{}.die(rand.New(rand.NewSource(1)))
+	winner := playGame(GameConfig{}, &StayAtK{20}, &StayAtK{25}, 0, dice, func(turn Turn) {
+		log = append(log, turn)
+	})
+	data, err := MarshalGame(winner, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var replay struct {
+		Winner int `json:"winner"`
+		Turns  []struct {
+			Player    int  `json:"player"`
+			Die       int  `json:"die"`
+			TurnTotal int  `json:"turnTotal"`
+			Ended     bool `json:"ended"`
+		} `json:"turns"`
+	}
+	if err := json.Unmarshal(data, &replay); err != nil {
+		t.Fatal(err)
+	}
+	if replay.Winner != winner || len(replay.Turns) != len(log) {
+		t.Fatalf("got winner %d and %d turns, want %d and %d", replay.Winner, len(replay.Turns), winner, len(log))
+	}
+	for i, turn := range replay.Turns {
+		if got := (Turn{turn.Player, turn.Die, turn.TurnTotal, turn.Ended}); got != log[i] {
+			t.Errorf("turn %d is %+v, want %+v", i, got, log[i])
+		}
+	}
+	var game Game
+	if err := json.Unmarshal(data, &game); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(game, Game{winner, log}) {
+		t.Errorf("unmarshaled %+v, want the game played", game)
+	}
+}
+
+func TestRunGameOutcomeIsConsistent(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		o := RunGame(&StayAtK{20}, &Optimal{}, seed)
+		if again := RunGame(&StayAtK{20}, &Optimal{}, seed); !reflect.DeepEqual(again, o) {
+			t.Fatalf("seed %d: RunGame gave %+v, then %+v", seed, o, again)
+		}
+		// Replay the transcript: an ended turn banks its total, the winner's
+		// last action reaches win without ending its turn, and players take
+		// turns starting with a.
+		var banked [2]int
+		player, turns := 0, 1
+		for i, turn := range o.Transcript {
+			if turn.Player != player {
+				t.Fatalf("seed %d: action %d is player %d's, want player %d's", seed, i, turn.Player, player)
+			}
+			if turn.Ended {
+				banked[player] += turn.TurnTotal
+				player = 1 - player
+				turns++
+			}
+		}
+		last := o.Transcript[len(o.Transcript)-1]
+		final := banked
+		final[last.Player] += last.TurnTotal
+		if last.Ended || last.Player != o.Winner || final != o.Scores || final[o.Winner] < win {
+			t.Errorf("seed %d: a transcript ending with %+v and banking %v doesn't give winner %d with %v",
+				seed, last, banked, o.Winner, o.Scores)
+		}
+		if turns != o.Turns {
+			t.Errorf("seed %d: the transcript starts %d turns, want %d", seed, turns, o.Turns)
+		}
+	}
+}
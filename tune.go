@@ -0,0 +1,115 @@
+package main
+
+import "math"
+
+// A Param describes one numeric parameter of a Tunable strategy.
+type Param struct {
+	Name     string
+	Min, Max float64 // The range of valid values, inclusive
+	Value    float64
+}
+
+// A Tunable strategy exposes its numeric parameters, so that it can be
+// optimized without knowing what they mean.
+type Tunable interface {
+	Strategy
+	Params() []Param
+	// WithParams returns a copy of the strategy with the parameters set to
+	// values, in the order of Params.
+	WithParams(values []float64) Strategy
+}
+
+func (self *StayAtK) Params() []Param {
+	return []Param{{"k", 1, win, float64(self.k)}}
+}
+
+func (self *StayAtK) WithParams(values []float64) Strategy {
+	return &StayAtK{int(values[0])}
+}
+
+func (self *Adaptive) Params() []Param {
+	return []Param{
+		{"behind", 1, win, float64(self.behind)},
+		{"even", 1, win, float64(self.even)},
+		{"ahead", 1, win, float64(self.ahead)},
+	}
+}
+
+func (self *Adaptive) WithParams(values []float64) Strategy {
+	return &Adaptive{int(values[0]), int(values[1]), int(values[2])}
+}
+
+// OptimizeStrategy hill-climbs proto's parameters to maximize its win rate
+// against opponent over games games seeded with seed, and returns the best
+// strategy found. Every candidate plays the same dice, so that differences
+// come from the parameters rather than luck. Each step tries moving each
+// parameter up and down by the step size, which starts at a quarter of the
+// parameter's range and halves whenever no move helps, down to 1.
+func OptimizeStrategy(proto Tunable, opponent Strategy, games int, seed int64) Tunable {
+	return hillClimb(proto, func(t Tunable) float64 {
+		return CompareStrategies(t, opponent, games, seed).winRateA()
+	})
+}
+
+// hillClimb does the search for OptimizeStrategy, maximizing rate.
+func hillClimb(proto Tunable, rate func(Tunable) float64) Tunable {
+	best, bestRate := proto, rate(proto)
+	params := proto.Params()
+	step := 0.0
+	for _, p := range params {
+		step = math.Max(step, math.Floor((p.Max-p.Min)/4))
+	}
+	for ; step >= 1; step = math.Floor(step / 2) {
+		for improved := true; improved; {
+			improved = false
+			for i := range params {
+				for _, delta := range []float64{-step, step} {
+					values := paramValues(best.Params())
+					values[i] = math.Min(math.Max(values[i]+delta, params[i].Min), params[i].Max)
+					candidate, ok := best.WithParams(values).(Tunable)
+					if !ok {
+						return best
+					}
+					if r := rate(candidate); r > bestRate {
+						best, bestRate, improved = candidate, r, true
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// paramValues returns the values of params.
+func paramValues(params []Param) []float64 {
+	values := make([]float64, len(params))
+	for i, p := range params {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// AutoTuneAndCompete tunes proto's parameters to maximize its win rate
+// against the whole of field, playing games games against each member, then
+// enters the tuned strategy in a round robin with field seeded with seed.
+// It returns the tuned strategy and its rank in the round robin, where 1 is
+// first; strategies with equal win rates share a rank.
+func AutoTuneAndCompete(proto Tunable, field []Strategy, games int, seed int64) (tuned Strategy, rank int) {
+	tuned = hillClimb(proto, func(t Tunable) float64 {
+		won, played := 0, 0
+		for j, opponent := range cloneAll(field) {
+			// Reseed each candidate's opponents, so that they all see the
+			// same dice and the same random choices.
+			if s, ok := opponent.(Seeder); ok {
+				s.Seed(seriesSeed(seed, -1, j))
+			}
+			reset(t, opponent)
+			c := CompareStrategies(t, opponent, games, seriesSeed(seed, 0, j))
+			won += c.WinsA
+			played += c.Games
+		}
+		return float64(won) / float64(played)
+	})
+	lineup := append(append([]Strategy(nil), field...), tuned)
+	return tuned, ranks(Simulate(lineup, games, seed).Standings)[len(field)]
+}
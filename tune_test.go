@@ -0,0 +1,27 @@
+package main
+
+import "testing"
+
+func TestOptimizeStrategyImprovesStayAtK(t *testing.T) {
+	proto, opponent := &StayAtK{5}, &StayAtK{25}
+	tuned := OptimizeStrategy(proto, opponent, 2000, 1)
+	k := tuned.(*StayAtK).k
+	if k < 15 || k > 30 {
+		t.Errorf("tuned k to %d, want somewhere near the usual 20 to 25", k)
+	}
+	// Check on fresh dice, so the tuned k hasn't just fit the ones it saw.
+	before := CompareStrategies(proto, opponent, 5000, 2).winRateA()
+	after := CompareStrategies(tuned, opponent, 5000, 2).winRateA()
+	if after <= before+0.05 {
+		t.Errorf("tuned k=%d wins %.3f against %v, want clearly more than the untuned %.3f",
+			k, after, opponent, before)
+	}
+}
+
+func TestAutoTuneAndCompeteRanksNearTheTop(t *testing.T) {
+	field := []Strategy{&StayAtK{5}, &StayAtK{10}, &StayAtK{40}, NewRandom(1), &StayAtK{60}}
+	tuned, rank := AutoTuneAndCompete(&StayAtK{1}, field, 500, 1)
+	if rank > 2 {
+		t.Errorf("tuned %v ranked %d of %d, want first or second", tuned, rank, len(field)+1)
+	}
+}